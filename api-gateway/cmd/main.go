@@ -1,14 +1,19 @@
 package main
 
 import (
-	"github.com/joho/godotenv"
 	"go.uber.org/fx"
 	_ "social-network/api-gateway/docs"
 	"social-network/api-gateway/internal/app"
 	"social-network/api-gateway/internal/client"
 	"social-network/api-gateway/internal/config"
-	"social-network/api-gateway/internal/logger"
+	"social-network/api-gateway/internal/proxy"
+	"social-network/api-gateway/internal/ratelimit"
 	"social-network/api-gateway/internal/server"
+	"social-network/pkg/certs"
+	"social-network/pkg/env"
+	"social-network/pkg/logger"
+	"social-network/pkg/serving"
+	"social-network/pkg/tracing"
 )
 
 // @title Swagger API-GATEWAY
@@ -20,16 +25,25 @@ import (
 // @BasePath /
 func main() {
 	addOpts := fx.Options(
+		env.Module,
+		logger.Module,
+		tracing.Module("api-gateway"),
+		certs.Module,
 		fx.Provide(
 			config.NewConfig,
-			client.NewGrpcConnection,
+			config.NewInternalTLS,
+			client.NewPostsConnection,
+			client.NewPostsClient,
+			client.NewUserGrpcClient,
+			client.NewUserServiceClient,
+			proxy.New,
 			app.NewApp,
+			ratelimit.NewStore,
+			ratelimit.NewLimiter,
+			server.NewHealthChecker,
 			server.NewServer,
 		),
-		fx.Invoke(
-			godotenv.Load,
-			logger.InitLogger,
-			server.InvokeServer,
-		))
+		serving.HTTPModule,
+	)
 	fx.New(addOpts).Run()
 }
@@ -15,9 +15,39 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/login": {
+        "/admin/outbox/requeue": {
             "post": {
-                "description": "Войти в систему",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Вернуть в очередь события outbox, отложенные после слишком многих неудачных попыток доставки, например удаление контента удаленного пользователя",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Повторить доставку событий",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/social-network_api-gateway_internal_models.RequeuedModel"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/role": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Назначить пользователю роль user, moderator или admin",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,33 +55,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Admin"
                 ],
-                "summary": "Войти",
+                "summary": "Изменить роль",
                 "parameters": [
                     {
-                        "description": "Войти в систему",
-                        "name": "user",
+                        "description": "Логин и новая роль",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/social-network_api-gateway_internal_models.LoginModel"
+                            "$ref": "#/definitions/social-network_api-gateway_internal_models.RoleChangeModel"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "string"
-                        }
+                        "description": "OK"
                     }
                 }
             }
         },
-        "/register": {
+        "/admin/unlock": {
             "post": {
-                "description": "Зарегистрироваться в сервисе",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Сбросить неудачные попытки входа и снять блокировку учетной записи",
                 "consumes": [
                     "application/json"
                 ],
@@ -59,64 +91,178 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Admin"
                 ],
-                "summary": "Регистрация",
+                "summary": "Снять блокировку входа",
                 "parameters": [
                     {
-                        "description": "Создать пользователя",
-                        "name": "user",
+                        "description": "Логин пользователя",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/social-network_api-gateway_internal_models.RegisterModel"
+                            "$ref": "#/definitions/social-network_api-gateway_internal_models.AdminUserModel"
                         }
                     }
                 ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/admin/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Найти пользователей по логину или email",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Список пользователей",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Часть логина или email",
+                        "name": "query",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы, до 100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Смещение",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/social-network_api-gateway_internal_models.UserPageModel"
                         }
                     }
                 }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удалить учетную запись пользователя",
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Удалить пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Логин пользователя",
+                        "name": "login",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
             }
         },
-        "/user-profile": {
-            "get": {
+        "/admin/users/logout": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Получить пользователя",
+                "description": "Отозвать все выданные пользователю токены",
                 "consumes": [
-                    "application/x-www-form-urlencoded"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Admin"
+                ],
+                "summary": "Завершить сессии",
+                "parameters": [
+                    {
+                        "description": "Логин пользователя",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/social-network_api-gateway_internal_models.AdminUserModel"
+                        }
+                    }
                 ],
-                "summary": "Получить пользователя",
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/admin/users/suspend": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Запретить пользователю вход и доступ к API",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Заблокировать пользователя",
+                "parameters": [
+                    {
+                        "description": "Логин и причина",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/social-network_api-gateway_internal_models.UserModel"
+                            "$ref": "#/definitions/social-network_api-gateway_internal_models.SuspendModel"
                         }
                     }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
                 }
-            },
-            "put": {
+            }
+        },
+        "/admin/users/unsuspend": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Обновить данные о пользователе",
+                "description": "Вернуть пользователю доступ",
                 "consumes": [
                     "application/json"
                 ],
@@ -124,17 +270,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Admin"
                 ],
-                "summary": "Обновить пользователя",
+                "summary": "Разблокировать пользователя",
                 "parameters": [
                     {
-                        "description": "Обновить пользователя",
-                        "name": "user",
+                        "description": "Логин пользователя",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/social-network_api-gateway_internal_models.UserModel"
+                            "$ref": "#/definitions/social-network_api-gateway_internal_models.AdminUserModel"
                         }
                     }
                 ],
@@ -144,72 +290,1240 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "social-network_api-gateway_internal_models.LoginModel": {
-            "type": "object",
-            "properties": {
-                "login": {
-                    "type": "string"
-                },
-                "password": {
-                    "type": "string"
+        },
+        "/avatars/{file}": {
+            "get": {
+                "description": "Получить изображение по пути из поля avatar профиля",
+                "produces": [
+                    "image/png",
+                    "image/jpeg",
+                    "image/gif",
+                    "image/webp"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Аватар пользователя",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя файла",
+                        "name": "file",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
                 }
             }
         },
-        "social-network_api-gateway_internal_models.RegisterModel": {
-            "type": "object",
-            "properties": {
-                "email": {
-                    "type": "string"
-                },
-                "login": {
-                    "type": "string"
-                },
-                "password": {
-                    "type": "string"
+        "/health/upstreams": {
+            "get": {
+                "description": "Состояние соединений шлюза с внутренними сервисами",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Состояние сервисов",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/social-network_api-gateway_internal_models.UpstreamHealth"
+                            }
+                        }
+                    }
                 }
             }
         },
-        "social-network_api-gateway_internal_models.UserModel": {
-            "type": "object",
-            "properties": {
-                "email": {
-                    "type": "string",
-                    "example": ""
-                },
-                "family_name": {
-                    "type": "string",
-                    "example": ""
-                },
-                "id": {
-                    "type": "integer",
-                    "default": 0
-                },
-                "login": {
-                    "type": "string",
-                    "example": ""
-                },
-                "name": {
-                    "type": "string",
-                    "example": ""
-                },
-                "password": {
-                    "type": "string",
-                    "example": ""
-                },
-                "phone": {
-                    "type": "string",
-                    "example": ""
-                },
-                "registered_at": {
-                    "type": "string",
-                    "example": "2023-10-01T00:00:00Z"
-                },
-                "updated_at": {
-                    "type": "string",
-                    "example": "2023-10-01T00:00:00Z"
+        "/login": {
+            "post": {
+                "description": "Войти в систему",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Войти",
+                "parameters": [
+                    {
+                        "description": "Войти в систему",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/social-network_api-gateway_internal_models.LoginModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Слишком много попыток входа",
+                        "schema": {
+                            "type": "string"
+                        },
+                        "headers": {
+                            "Retry-After": {
+                                "type": "integer",
+                                "description": "Через сколько секунд можно повторить попытку"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/password-reset/confirm": {
+            "post": {
+                "description": "Установить новый пароль по токену из письма. Ссылка из письма открывает страницу /reset-password фронтенда (APP_BASE_URL), которая запрашивает новый пароль и отправляет этот запрос. Снимает блокировку входа и отзывает все выданные ранее токены",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Сбросить пароль",
+                "parameters": [
+                    {
+                        "description": "Токен и новый пароль",
+                        "name": "confirm",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/social-network_api-gateway_internal_models.PasswordResetConfirmModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/password-reset/request": {
+            "post": {
+                "description": "Отправить письмо со ссылкой для сброса пароля",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Запросить сброс пароля",
+                "parameters": [
+                    {
+                        "description": "Email пользователя",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/social-network_api-gateway_internal_models.PasswordResetRequestModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted"
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "Зарегистрироваться в сервисе",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Регистрация",
+                "parameters": [
+                    {
+                        "description": "Создать пользователя",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/social-network_api-gateway_internal_models.RegisterModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/user-profile": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получить пользователя",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Получить пользователя",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/social-network_api-gateway_internal_models.UserModel"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Обновить данные о пользователе",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Обновить пользователя",
+                "parameters": [
+                    {
+                        "description": "Обновить пользователя",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/social-network_api-gateway_internal_models.UserModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Запланировать удаление учетной записи, постов и комментариев. До окончания срока удаление можно отменить",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Удалить учетную запись",
+                "parameters": [
+                    {
+                        "description": "Текущий пароль",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/social-network_api-gateway_internal_models.AccountDeletionModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/social-network_api-gateway_internal_models.AccountDeletionResponseModel"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Обновить только переданные поля (JSON Merge Patch), null очищает поле",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Частично обновить пользователя",
+                "parameters": [
+                    {
+                        "description": "Изменяемые поля",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/social-network_api-gateway_internal_models.UserProfilePatchModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/user-profile/avatar": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Загрузить изображение профиля в формате PNG, JPEG, GIF или WebP размером до 2 МБ",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Загрузить аватар",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Изображение",
+                        "name": "avatar",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/social-network_api-gateway_internal_models.AvatarModel"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Удалить изображение профиля",
+                "tags": [
+                    "User"
+                ],
+                "summary": "Удалить аватар",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/user-profile/email": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Сменить email, требуется текущий пароль",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Сменить email",
+                "parameters": [
+                    {
+                        "description": "Пароль и новый email",
+                        "name": "change",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/social-network_api-gateway_internal_models.EmailChangeModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/user-profile/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Скачать zip-архив с профилем, постами и жалобами в формате JSON",
+                "produces": [
+                    "application/zip"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Выгрузить данные",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            }
+        },
+        "/user-profile/password": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Сменить пароль, требуется текущий пароль. Все выданные ранее токены, включая текущий, отзываются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Сменить пароль",
+                "parameters": [
+                    {
+                        "description": "Текущий и новый пароль",
+                        "name": "change",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/social-network_api-gateway_internal_models.PasswordChangeModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/user-profile/privacy": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Выбрать, какие поля профиля видны другим пользователям. Не переданные поля скрываются",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Настройки приватности",
+                "parameters": [
+                    {
+                        "description": "Публичные поля",
+                        "name": "privacy",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/social-network_api-gateway_internal_models.PrivacyModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/user-profile/restore": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Отменить запланированное удаление учетной записи",
+                "tags": [
+                    "User"
+                ],
+                "summary": "Отменить удаление учетной записи",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/user-profile/{kind}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получить пользователей, которых вы заблокировали (blocks) или скрыли из ленты (mutes)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Заблокированные и скрытые пользователи",
+                "parameters": [
+                    {
+                        "enum": [
+                            "blocks",
+                            "mutes"
+                        ],
+                        "type": "string",
+                        "description": "blocks или mutes",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/social-network_api-gateway_internal_models.RelatedUserModel"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Блокировка скрывает посты друг от друга, скрытие убирает посты пользователя только из вашей ленты",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Заблокировать или скрыть пользователя",
+                "parameters": [
+                    {
+                        "enum": [
+                            "blocks",
+                            "mutes"
+                        ],
+                        "type": "string",
+                        "description": "blocks или mutes",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Логин пользователя",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/social-network_api-gateway_internal_models.RelationModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Снять блокировку или скрытие",
+                "tags": [
+                    "User"
+                ],
+                "summary": "Разблокировать или вернуть пользователя",
+                "parameters": [
+                    {
+                        "enum": [
+                            "blocks",
+                            "mutes"
+                        ],
+                        "type": "string",
+                        "description": "blocks или mutes",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Логин пользователя",
+                        "name": "login",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/users/by-login/{login}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получить публичный профиль пользователя по логину",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Профиль пользователя по логину",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Логин пользователя",
+                        "name": "login",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/social-network_api-gateway_internal_models.PublicProfileModel"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Получить публичный профиль пользователя по id или логину. Поля, скрытые настройками приватности, не возвращаются",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Профиль пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Id пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/social-network_api-gateway_internal_models.PublicProfileModel"
+                        }
+                    }
+                }
+            }
+        },
+        "/verify-email": {
+            "post": {
+                "description": "Подтвердить email по токену из письма. Ссылка из письма открывает страницу /verify-email фронтенда (APP_BASE_URL), которая отправляет этот запрос",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Подтвердить email",
+                "parameters": [
+                    {
+                        "description": "Токен из письма",
+                        "name": "verification",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/social-network_api-gateway_internal_models.EmailVerificationModel"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/verify-email/resend": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Повторно отправить письмо для подтверждения email",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Отправить письмо повторно",
+                "responses": {
+                    "202": {
+                        "description": "Accepted"
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "social-network_api-gateway_internal_models.AccountDeletionModel": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "social-network_api-gateway_internal_models.AccountDeletionResponseModel": {
+            "type": "object",
+            "properties": {
+                "delete_after": {
+                    "type": "string"
+                }
+            }
+        },
+        "social-network_api-gateway_internal_models.AdminUserModel": {
+            "type": "object",
+            "properties": {
+                "login": {
+                    "type": "string"
+                }
+            }
+        },
+        "social-network_api-gateway_internal_models.AvatarModel": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "type": "string",
+                    "example": "/avatars/1-abc.png"
+                }
+            }
+        },
+        "social-network_api-gateway_internal_models.EmailChangeModel": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "social-network_api-gateway_internal_models.EmailVerificationModel": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "social-network_api-gateway_internal_models.LoginModel": {
+            "type": "object",
+            "properties": {
+                "login": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "social-network_api-gateway_internal_models.PasswordChangeModel": {
+            "type": "object",
+            "properties": {
+                "current_password": {
+                    "type": "string"
+                },
+                "new_password": {
+                    "type": "string"
+                }
+            }
+        },
+        "social-network_api-gateway_internal_models.PasswordResetConfirmModel": {
+            "type": "object",
+            "properties": {
+                "new_password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "social-network_api-gateway_internal_models.PasswordResetRequestModel": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "social-network_api-gateway_internal_models.PrivacyModel": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "type": "boolean",
+                    "default": true
+                },
+                "bio": {
+                    "type": "boolean",
+                    "default": true
+                },
+                "birthday": {
+                    "type": "boolean",
+                    "default": false
+                },
+                "family_name": {
+                    "type": "boolean",
+                    "default": true
+                },
+                "interests": {
+                    "type": "boolean",
+                    "default": true
+                },
+                "name": {
+                    "type": "boolean",
+                    "default": true
+                },
+                "posts_count": {
+                    "type": "boolean",
+                    "default": true
+                },
+                "registered_at": {
+                    "type": "boolean",
+                    "default": true
+                }
+            }
+        },
+        "social-network_api-gateway_internal_models.PublicProfileModel": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "type": "string"
+                },
+                "bio": {
+                    "type": "string"
+                },
+                "birthday": {
+                    "type": "string"
+                },
+                "family_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "interests": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "login": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "posts_count": {
+                    "type": "integer"
+                },
+                "registered_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "social-network_api-gateway_internal_models.RegisterModel": {
+            "type": "object",
+            "required": [
+                "email",
+                "login",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "maxLength": 254
+                },
+                "login": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "minLength": 3
+                },
+                "password": {
+                    "type": "string",
+                    "maxLength": 72,
+                    "minLength": 8
+                }
+            }
+        },
+        "social-network_api-gateway_internal_models.RelatedUserModel": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "login": {
+                    "type": "string"
+                }
+            }
+        },
+        "social-network_api-gateway_internal_models.RelationModel": {
+            "type": "object",
+            "properties": {
+                "login": {
+                    "type": "string"
+                }
+            }
+        },
+        "social-network_api-gateway_internal_models.RequeuedModel": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "integer"
+                }
+            }
+        },
+        "social-network_api-gateway_internal_models.RoleChangeModel": {
+            "type": "object",
+            "properties": {
+                "login": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "user",
+                        "moderator",
+                        "admin"
+                    ]
+                }
+            }
+        },
+        "social-network_api-gateway_internal_models.SuspendModel": {
+            "type": "object",
+            "properties": {
+                "login": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "social-network_api-gateway_internal_models.UpstreamHealth": {
+            "type": "object",
+            "properties": {
+                "breaker": {
+                    "type": "string",
+                    "enum": [
+                        "closed",
+                        "open",
+                        "half-open"
+                    ]
+                },
+                "connection": {
+                    "type": "string",
+                    "enum": [
+                        "IDLE",
+                        "CONNECTING",
+                        "READY",
+                        "TRANSIENT_FAILURE",
+                        "SHUTDOWN"
+                    ]
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "social-network_api-gateway_internal_models.UserModel": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": ""
+                },
+                "avatar": {
+                    "type": "string",
+                    "example": "/avatars/1-abc.png"
+                },
+                "bio": {
+                    "type": "string",
+                    "example": ""
+                },
+                "birthday": {
+                    "type": "string",
+                    "example": "1990-01-31"
+                },
+                "email": {
+                    "type": "string",
+                    "example": ""
+                },
+                "email_verified": {
+                    "type": "boolean",
+                    "default": false
+                },
+                "family_name": {
+                    "type": "string",
+                    "example": ""
+                },
+                "id": {
+                    "type": "integer",
+                    "default": 0
+                },
+                "interests": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "music",
+                        "travel"
+                    ]
+                },
+                "login": {
+                    "type": "string",
+                    "example": ""
+                },
+                "name": {
+                    "type": "string",
+                    "example": ""
+                },
+                "password": {
+                    "type": "string",
+                    "example": ""
+                },
+                "phone": {
+                    "type": "string",
+                    "example": ""
+                },
+                "privacy": {
+                    "$ref": "#/definitions/social-network_api-gateway_internal_models.PrivacyModel"
+                },
+                "private_info": {
+                    "type": "string",
+                    "example": ""
+                },
+                "registered_at": {
+                    "type": "string",
+                    "example": "2023-10-01T00:00:00Z"
+                },
+                "role": {
+                    "type": "string",
+                    "default": "user",
+                    "enum": [
+                        "user",
+                        "moderator",
+                        "admin"
+                    ]
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2023-10-01T00:00:00Z"
+                }
+            }
+        },
+        "social-network_api-gateway_internal_models.UserPageModel": {
+            "type": "object",
+            "properties": {
+                "total": {
+                    "type": "integer"
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/social-network_api-gateway_internal_models.UserSummaryModel"
+                    }
+                }
+            }
+        },
+        "social-network_api-gateway_internal_models.UserProfilePatchModel": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": ""
+                },
+                "bio": {
+                    "type": "string",
+                    "example": ""
+                },
+                "birthday": {
+                    "type": "string",
+                    "example": "1990-01-31"
+                },
+                "family_name": {
+                    "type": "string",
+                    "example": ""
+                },
+                "interests": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "music",
+                        "travel"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "example": ""
+                },
+                "phone": {
+                    "type": "string",
+                    "example": ""
+                },
+                "private_info": {
+                    "type": "string",
+                    "example": ""
+                }
+            }
+        },
+        "social-network_api-gateway_internal_models.UserSummaryModel": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "email_verified": {
+                    "type": "boolean"
+                },
+                "family_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "locked_until": {
+                    "type": "string"
+                },
+                "login": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "registered_at": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "suspend_reason": {
+                    "type": "string"
+                },
+                "suspended_at": {
+                    "type": "string"
                 }
             }
         }
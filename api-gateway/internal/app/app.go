@@ -3,42 +3,65 @@ package app
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
+	"net"
 	"net/http"
-	"net/http/httputil"
-	"net/url"
-	"social-network/api-gateway/internal/logger"
-	_ "social-network/api-gateway/internal/models"
+	"slices"
+	"social-network/api-gateway/internal/client"
+	"social-network/api-gateway/internal/config"
+	customErrors "social-network/api-gateway/internal/errors"
+	"social-network/api-gateway/internal/models"
+	"social-network/api-gateway/internal/proxy"
+	"social-network/pkg/internaltoken"
+	"social-network/pkg/logger"
+	"social-network/pkg/throttle"
+	"social-network/pkg/validation"
 	pb "social-network/protos"
 	"strconv"
 	"strings"
 
-	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 type App struct {
-	grpcClient pb.PostsServiceClient
+	proxy          *proxy.Proxy
+	postsConn      *client.PostsConnection
+	grpcClient     pb.PostsServiceClient
+	userGrpcClient pb.UserServiceClient
+	userClient     *client.UserServiceClient
+	loginThrottle  *throttle.Throttle
 }
 
-func NewApp(client pb.PostsServiceClient) *App {
+func NewApp(
+	proxy *proxy.Proxy,
+	postsConn *client.PostsConnection,
+	grpcClient pb.PostsServiceClient,
+	userGrpcClient pb.UserServiceClient,
+	userClient *client.UserServiceClient,
+	cfg *config.Config,
+) *App {
 	return &App{
-		grpcClient: client,
+		proxy:          proxy,
+		postsConn:      postsConn,
+		grpcClient:     grpcClient,
+		userGrpcClient: userGrpcClient,
+		userClient:     userClient,
+		loginThrottle:  throttle.New(cfg.LoginIPFreeAttempts, cfg.LoginIPBaseDelay, cfg.LoginIPMaxDelay, cfg.LoginIPFailureDecay),
 	}
 }
 
-func (a *App) createProxy(host string) *httputil.ReverseProxy {
-	proxy := httputil.NewSingleHostReverseProxy(&url.URL{
-		Scheme: "http",
-		Host:   host,
-	})
-
-	proxy.Director = func(req *http.Request) {
-		req.URL.Scheme = "http"
-		req.URL.Host = host
-		req.Host = host
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
 	}
-	return proxy
+	return host
 }
 
 // Register godoc
@@ -51,16 +74,44 @@ func (a *App) createProxy(host string) *httputil.ReverseProxy {
 // @Success      200  {string} string
 // @Router       /register [post]
 func (a *App) Register(w http.ResponseWriter, r *http.Request) {
-	logger.Info("POST /register")
+	logger.InfoContext(r.Context(), "POST /register")
 
 	if r.Method != http.MethodPost {
-		logger.Error("POST /register: method not allowed")
+		logger.ErrorContext(r.Context(), "POST /register: method not allowed")
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
 
-	proxy := a.createProxy("user-service:8081")
-	proxy.ServeHTTP(w, r)
+	var user models.RegisterModel
+	data, _ := io.ReadAll(r.Body)
+	err := json.Unmarshal(data, &user)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = validation.Struct(&user)
+	if err != nil {
+		var validationErr *customErrors.ValidationError
+		if errors.As(err, &validationErr) {
+			writeValidationError(w, validationErr)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	token, err := a.userGrpcClient.Register(client.ServiceContext(r.Context()), &pb.RegisterRequest{
+		Login:    user.Login,
+		Email:    user.Email,
+		Password: user.Password,
+	})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Register failed", "error", err)
+		writeCredentialsError(w, err)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(token.GetToken())
 }
 
 // Login godoc
@@ -71,18 +122,47 @@ func (a *App) Register(w http.ResponseWriter, r *http.Request) {
 // @Produce      json
 // @Param 		 user body models.LoginModel true "Войти в систему"
 // @Success      200  {string} string
+// @Failure      429  {string} string "Слишком много попыток входа"
+// @Header       429  {integer} Retry-After "Через сколько секунд можно повторить попытку"
 // @Router       /login [post]
 func (a *App) Login(w http.ResponseWriter, r *http.Request) {
-	logger.Info("POST /login")
+	logger.InfoContext(r.Context(), "POST /login")
 
 	if r.Method != http.MethodPost {
-		logger.Error("POST /login: method not allowed")
+		logger.ErrorContext(r.Context(), "POST /login: method not allowed")
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
 
-	proxy := a.createProxy("user-service:8081")
-	proxy.ServeHTTP(w, r)
+	ip := clientIP(r)
+	if retryAfter, ok := a.loginThrottle.Allow(ip); !ok {
+		logger.WarnContext(r.Context(), "POST /login: too many attempts", "ip", ip)
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+
+	var user models.LoginModel
+	err := json.NewDecoder(r.Body).Decode(&user)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	token, err := a.userGrpcClient.Login(client.ServiceContext(r.Context()), &pb.LoginRequest{
+		Login:    user.Login,
+		Password: user.Password,
+		ClientIp: ip,
+	})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			a.loginThrottle.Failure(ip)
+		}
+		logger.ErrorContext(r.Context(), "Login failed", "error", err)
+		writeCredentialsError(w, err)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(token.GetToken())
 }
 
 // GetUserProfile godoc
@@ -95,23 +175,30 @@ func (a *App) Login(w http.ResponseWriter, r *http.Request) {
 // @Success      200  {object} models.UserModel
 // @Router       /user-profile [get]
 func (a *App) GetUserProfile(w http.ResponseWriter, r *http.Request) {
-	logger.Info("GET /user-profile")
+	logger.InfoContext(r.Context(), "GET /user-profile")
 
 	if r.Method != http.MethodGet {
-		logger.Error("GET /user-profile: method not allowed")
+		logger.ErrorContext(r.Context(), "GET /user-profile: method not allowed")
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
 
-	err := JWTTokenVerify(r)
+	err := a.JWTTokenVerify(r)
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		_, _ = fmt.Fprintf(w, err.Error())
+		writeAuthError(w, err)
 		return
 	}
 
-	proxy := a.createProxy("user-service:8081")
-	proxy.ServeHTTP(w, r)
+	user, err := a.userGrpcClient.GetUser(outgoingContext(r), &pb.GetUserRequest{
+		Key: &pb.GetUserRequest_Login{Login: r.Header.Get("login")},
+	})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Get user failed", "error", err)
+		writeGrpcError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(toUserModel(user))
 }
 
 // UpdateUserProfile godoc
@@ -125,202 +212,1150 @@ func (a *App) GetUserProfile(w http.ResponseWriter, r *http.Request) {
 // @Success      200
 // @Router       /user-profile [put]
 func (a *App) UpdateUserProfile(w http.ResponseWriter, r *http.Request) {
-	logger.Info("PUT /user-profile")
+	logger.InfoContext(r.Context(), "PUT /user-profile")
 
 	if r.Method != http.MethodPut {
-		logger.Error("PUT /user-profile: method not allowed")
+		logger.ErrorContext(r.Context(), "PUT /user-profile: method not allowed")
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
 
-	err := JWTTokenVerify(r)
+	err := a.JWTTokenVerify(r)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+
+	var user models.UserModel
+	err = json.NewDecoder(r.Body).Decode(&user)
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		_, _ = fmt.Fprintf(w, err.Error())
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if user.Login != "" || user.Email != "" || user.Password != "" {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprint(w, updateCredentialsMessage)
 		return
 	}
 
-	proxy := a.createProxy("user-service:8081")
-	proxy.ServeHTTP(w, r)
+	userId, _ := strconv.Atoi(r.Header.Get("user_id"))
+	_, err = a.userGrpcClient.UpdateUser(outgoingContext(r), &pb.UpdateUserRequest{
+		Id:   int32(userId),
+		User: profileFromUserModel(&user),
+	})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Update user failed", "error", err)
+		writeGrpcError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
 }
 
-func (a *App) CreatePost(w http.ResponseWriter, r *http.Request) {
-	logger.Info("POST /post")
-	if r.Method != http.MethodPost {
-		logger.Error("POST /post: method not allowed")
+// PatchUserProfile godoc
+// @Summary      Частично обновить пользователя
+// @Description  Обновить только переданные поля (JSON Merge Patch), null очищает поле
+// @Tags         User
+// @Accept		 json
+// @Security BearerAuth
+// @Produce      json
+// @Param 		 user body models.UserProfilePatchModel true "Изменяемые поля"
+// @Success      200
+// @Router       /user-profile [patch]
+func (a *App) PatchUserProfile(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "PATCH /user-profile")
+
+	if r.Method != http.MethodPatch {
+		logger.ErrorContext(r.Context(), "PATCH /user-profile: method not allowed")
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
 
-	err := JWTTokenVerify(r)
+	err := a.JWTTokenVerify(r)
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		_, _ = fmt.Fprintf(w, err.Error())
+		writeAuthError(w, err)
 		return
 	}
 
-	var post pb.PostEssential
 	data, _ := io.ReadAll(r.Body)
-	err = json.Unmarshal(data, &post)
+	user, mask, err := decodeProfileMergePatch(data)
 	if err != nil {
 		w.WriteHeader(http.StatusBadRequest)
-		_, _ = fmt.Fprintf(w, err.Error())
+		_, _ = fmt.Fprint(w, err.Error())
+		return
+	}
+	// an empty patch changes nothing, while an empty mask would replace the whole profile
+	if len(mask.GetPaths()) == 0 {
+		w.WriteHeader(http.StatusOK)
 		return
 	}
 
-	userID := r.Header.Get("user_id")
-	md := metadata.Pairs("user_id", userID)
-
-	ctx := metadata.NewOutgoingContext(r.Context(), md)
-	_, err = a.grpcClient.AddPost(ctx, &post)
+	userId, _ := strconv.Atoi(r.Header.Get("user_id"))
+	_, err = a.userGrpcClient.UpdateUser(outgoingContext(r), &pb.UpdateUserRequest{
+		Id:         int32(userId),
+		User:       user,
+		UpdateMask: mask,
+	})
 	if err != nil {
-		logger.Error(fmt.Sprintf("Add post failed: %v", err))
-		w.WriteHeader(http.StatusInternalServerError)
+		logger.ErrorContext(r.Context(), "Patch user failed", "error", err)
+		writeGrpcError(w, err)
+		return
 	}
+	w.WriteHeader(http.StatusOK)
 }
 
-func (a *App) DeletePost(w http.ResponseWriter, r *http.Request) {
-	logger.Info("DELETE /post")
-	if r.Method != http.MethodDelete {
-		logger.Error("DELETE /post: method not allowed")
+// ChangePassword godoc
+// @Summary      Сменить пароль
+// @Description  Сменить пароль, требуется текущий пароль. Все выданные ранее токены, включая текущий, отзываются
+// @Tags         User
+// @Accept		 json
+// @Security BearerAuth
+// @Produce      json
+// @Param 		 change body models.PasswordChangeModel true "Текущий и новый пароль"
+// @Success      200
+// @Router       /user-profile/password [post]
+func (a *App) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /user-profile/password")
+
+	if r.Method != http.MethodPost {
+		logger.ErrorContext(r.Context(), "POST /user-profile/password: method not allowed")
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
 
-	err := JWTTokenVerify(r)
+	err := a.JWTTokenVerify(r)
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		_, _ = fmt.Fprintf(w, err.Error())
+		writeAuthError(w, err)
 		return
 	}
 
-	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	a.proxy.ServeHTTP(w, r)
+}
+
+// ChangeEmail godoc
+// @Summary      Сменить email
+// @Description  Сменить email, требуется текущий пароль
+// @Tags         User
+// @Accept		 json
+// @Security BearerAuth
+// @Produce      json
+// @Param 		 change body models.EmailChangeModel true "Пароль и новый email"
+// @Success      200
+// @Router       /user-profile/email [post]
+func (a *App) ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /user-profile/email")
+
+	if r.Method != http.MethodPost {
+		logger.ErrorContext(r.Context(), "POST /user-profile/email: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	err := a.JWTTokenVerify(r)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeAuthError(w, err)
 		return
 	}
 
-	message := pb.PostId{
-		PostId: int32(id),
+	a.proxy.ServeHTTP(w, r)
+}
+
+// VerifyEmail godoc
+// @Summary      Подтвердить email
+// @Description  Подтвердить email по токену из письма. Ссылка из письма открывает страницу /verify-email фронтенда (APP_BASE_URL), которая отправляет этот запрос
+// @Tags         Auth
+// @Accept		 json
+// @Produce      json
+// @Param 		 verification body models.EmailVerificationModel true "Токен из письма"
+// @Success      200
+// @Router       /verify-email [post]
+func (a *App) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /verify-email")
+
+	if r.Method != http.MethodPost {
+		logger.ErrorContext(r.Context(), "POST /verify-email: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	a.proxy.ServeHTTP(w, r)
+}
+
+// ResendEmailVerification godoc
+// @Summary      Отправить письмо повторно
+// @Description  Повторно отправить письмо для подтверждения email
+// @Tags         Auth
+// @Accept		 json
+// @Security BearerAuth
+// @Produce      json
+// @Success      202
+// @Router       /verify-email/resend [post]
+func (a *App) ResendEmailVerification(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /verify-email/resend")
+
+	if r.Method != http.MethodPost {
+		logger.ErrorContext(r.Context(), "POST /verify-email/resend: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
 	}
-	_, err = a.grpcClient.DeletePost(context.Background(), &message)
+
+	err := a.JWTTokenVerify(r)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Delete post failed: %v", err))
-		w.WriteHeader(http.StatusInternalServerError)
-		_, _ = fmt.Fprintf(w, err.Error())
+		writeAuthError(w, err)
 		return
 	}
+
+	a.proxy.ServeHTTP(w, r)
 }
 
-func (a *App) UpdatePost(w http.ResponseWriter, r *http.Request) {
-	logger.Info("PUT /post")
+// UpdatePrivacy godoc
+// @Summary      Настройки приватности
+// @Description  Выбрать, какие поля профиля видны другим пользователям. Не переданные поля скрываются
+// @Tags         User
+// @Accept		 json
+// @Security BearerAuth
+// @Param 		 privacy body models.PrivacyModel true "Публичные поля"
+// @Success      200
+// @Router       /user-profile/privacy [put]
+func (a *App) UpdatePrivacy(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "PUT /user-profile/privacy")
+
 	if r.Method != http.MethodPut {
-		logger.Error("PUT /post: method not allowed")
+		logger.ErrorContext(r.Context(), "PUT /user-profile/privacy: method not allowed")
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
 
-	err := JWTTokenVerify(r)
+	err := a.JWTTokenVerify(r)
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		_, _ = fmt.Fprintf(w, err.Error())
+		writeAuthError(w, err)
 		return
 	}
 
-	var post pb.PostWithNoUser
-	data, _ := io.ReadAll(r.Body)
-	err = json.Unmarshal(data, &post)
+	a.proxy.ServeHTTP(w, r)
+}
+
+// UploadAvatar godoc
+// @Summary      Загрузить аватар
+// @Description  Загрузить изображение профиля в формате PNG, JPEG, GIF или WebP размером до 2 МБ
+// @Tags         User
+// @Accept		 multipart/form-data
+// @Security BearerAuth
+// @Produce      json
+// @Param 		 avatar formData file true "Изображение"
+// @Success      200  {object} models.AvatarModel
+// @Router       /user-profile/avatar [post]
+func (a *App) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /user-profile/avatar")
+
+	if r.Method != http.MethodPost {
+		logger.ErrorContext(r.Context(), "POST /user-profile/avatar: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	err := a.JWTTokenVerify(r)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeAuthError(w, err)
+		return
+	}
+
+	a.proxy.ServeHTTP(w, r)
+}
+
+// DeleteAvatar godoc
+// @Summary      Удалить аватар
+// @Description  Удалить изображение профиля
+// @Tags         User
+// @Security BearerAuth
+// @Success      204
+// @Router       /user-profile/avatar [delete]
+func (a *App) DeleteAvatar(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "DELETE /user-profile/avatar")
+
+	if r.Method != http.MethodDelete {
+		logger.ErrorContext(r.Context(), "DELETE /user-profile/avatar: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
 
-	_, err = a.grpcClient.UpdatePost(context.Background(), &post)
+	err := a.JWTTokenVerify(r)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Update post failed: %v", err))
-		w.WriteHeader(http.StatusInternalServerError)
-		_, _ = fmt.Fprintf(w, err.Error())
+		writeAuthError(w, err)
+		return
 	}
+
+	a.proxy.ServeHTTP(w, r)
 }
 
-func (a *App) GetPostById(w http.ResponseWriter, r *http.Request) {
-	logger.Info("GET /get-post-by-id")
+// GetAvatar godoc
+// @Summary      Аватар пользователя
+// @Description  Получить изображение по пути из поля avatar профиля
+// @Tags         Users
+// @Produce      image/png,image/jpeg,image/gif,image/webp
+// @Param 		 file path string true "Имя файла"
+// @Success      200
+// @Router       /avatars/{file} [get]
+func (a *App) GetAvatar(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		logger.ErrorContext(r.Context(), "GET "+r.URL.Path+": method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	a.proxy.ServeHTTP(w, r)
+}
+
+// GetPublicProfile godoc
+// @Summary      Профиль пользователя
+// @Description  Получить публичный профиль пользователя по id или логину. Поля, скрытые настройками приватности, не возвращаются
+// @Tags         Users
+// @Security BearerAuth
+// @Produce      json
+// @Param 		 id path int true "Id пользователя"
+// @Success      200  {object} models.PublicProfileModel
+// @Router       /users/{id} [get]
+func (a *App) GetPublicProfile(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "GET "+r.URL.Path)
+
 	if r.Method != http.MethodGet {
-		logger.Error("GET /get-post-by-id: method not allowed")
+		logger.ErrorContext(r.Context(), "GET "+r.URL.Path+": method not allowed")
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
 
-	err := JWTTokenVerify(r)
+	err := a.JWTTokenVerify(r)
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		_, _ = fmt.Fprintf(w, err.Error())
+		writeAuthError(w, err)
 		return
 	}
 
-	split := strings.Split(r.URL.Path, "/")
-	id, err := strconv.Atoi(split[len(split)-1])
+	a.proxy.ServeHTTP(w, r)
+}
+
+// GetPublicProfileByLogin godoc
+// @Summary      Профиль пользователя по логину
+// @Description  Получить публичный профиль пользователя по логину
+// @Tags         Users
+// @Security BearerAuth
+// @Produce      json
+// @Param 		 login path string true "Логин пользователя"
+// @Success      200  {object} models.PublicProfileModel
+// @Router       /users/by-login/{login} [get]
+func (a *App) GetPublicProfileByLogin(w http.ResponseWriter, r *http.Request) {
+	a.GetPublicProfile(w, r)
+}
+
+// DeleteAccount godoc
+// @Summary      Удалить учетную запись
+// @Description  Запланировать удаление учетной записи, постов и комментариев. До окончания срока удаление можно отменить
+// @Tags         User
+// @Accept		 json
+// @Security BearerAuth
+// @Produce      json
+// @Param 		 request body models.AccountDeletionModel true "Текущий пароль"
+// @Success      202  {object} models.AccountDeletionResponseModel
+// @Router       /user-profile [delete]
+func (a *App) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "DELETE /user-profile")
+
+	if r.Method != http.MethodDelete {
+		logger.ErrorContext(r.Context(), "DELETE /user-profile: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	err := a.JWTTokenVerify(r)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		writeAuthError(w, err)
 		return
 	}
 
-	message := pb.PostId{
-		PostId: int32(id),
+	a.proxy.ServeHTTP(w, r)
+}
+
+// RestoreAccount godoc
+// @Summary      Отменить удаление учетной записи
+// @Description  Отменить запланированное удаление учетной записи
+// @Tags         User
+// @Security BearerAuth
+// @Success      200
+// @Router       /user-profile/restore [post]
+func (a *App) RestoreAccount(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /user-profile/restore")
+
+	if r.Method != http.MethodPost {
+		logger.ErrorContext(r.Context(), "POST /user-profile/restore: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
 	}
-	post, err := a.grpcClient.GetPostById(context.Background(), &message)
+
+	err := a.JWTTokenVerify(r)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Get post failed: %v", err))
-		w.WriteHeader(http.StatusInternalServerError)
-		_, _ = fmt.Fprintf(w, err.Error())
+		writeAuthError(w, err)
 		return
 	}
 
-	_ = json.NewEncoder(w).Encode(post)
+	a.proxy.ServeHTTP(w, r)
 }
 
-func (a *App) GetPosts(w http.ResponseWriter, r *http.Request) {
-	logger.Info("GET /get-posts")
+// ExportUserData godoc
+// @Summary      Выгрузить данные
+// @Description  Скачать zip-архив с профилем, постами и жалобами в формате JSON
+// @Tags         User
+// @Security BearerAuth
+// @Produce      application/zip
+// @Success      200  {file} file
+// @Router       /user-profile/export [get]
+func (a *App) ExportUserData(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "GET /user-profile/export")
+
 	if r.Method != http.MethodGet {
-		logger.Error("GET /get-posts: method not allowed")
+		logger.ErrorContext(r.Context(), "GET /user-profile/export: method not allowed")
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
 
-	err := JWTTokenVerify(r)
+	err := a.JWTTokenVerify(r)
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		_, _ = fmt.Fprintf(w, err.Error())
+		writeAuthError(w, err)
 		return
 	}
 
-	pageSize, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	login := r.Header.Get("login")
+	profile, err := a.userClient.ExportUser(r.Context(), login)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		logger.ErrorContext(r.Context(), "Export user failed", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	index, err := strconv.Atoi(r.URL.Query().Get("offset"))
+	content, err := a.grpcClient.ExportUserContent(outgoingContext(r), &emptypb.Empty{})
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+		logger.ErrorContext(r.Context(), "Export user content failed", "error", err)
+		writeGrpcError(w, err)
 		return
 	}
 
-	userID := r.Header.Get("user_id")
-	md := metadata.Pairs("user_id", userID)
-	ctx := metadata.NewOutgoingContext(r.Context(), md)
+	archive, err := buildExportArchive(profile, content)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Build export archive failed", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
 
-	pagination := pb.Pagination{
-		PageSize:  int32(pageSize),
-		PageIndex: int32(index),
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-export.zip"`, login))
+	_, _ = w.Write(archive)
+}
+
+// ListRelations godoc
+// @Summary      Заблокированные и скрытые пользователи
+// @Description  Получить пользователей, которых вы заблокировали (blocks) или скрыли из ленты (mutes)
+// @Tags         User
+// @Security BearerAuth
+// @Produce      json
+// @Param 		 kind path string true "blocks или mutes" Enums(blocks, mutes)
+// @Success      200  {array} models.RelatedUserModel
+// @Router       /user-profile/{kind} [get]
+func (a *App) ListRelations(w http.ResponseWriter, r *http.Request) {
+	a.proxyRelation(w, r, http.MethodGet)
+}
+
+// AddRelation godoc
+// @Summary      Заблокировать или скрыть пользователя
+// @Description  Блокировка скрывает посты друг от друга, скрытие убирает посты пользователя только из вашей ленты
+// @Tags         User
+// @Accept		 json
+// @Security BearerAuth
+// @Produce      json
+// @Param 		 kind path string true "blocks или mutes" Enums(blocks, mutes)
+// @Param 		 request body models.RelationModel true "Логин пользователя"
+// @Success      200
+// @Router       /user-profile/{kind} [post]
+func (a *App) AddRelation(w http.ResponseWriter, r *http.Request) {
+	a.proxyRelation(w, r, http.MethodPost)
+}
+
+// RemoveRelation godoc
+// @Summary      Разблокировать или вернуть пользователя
+// @Description  Снять блокировку или скрытие
+// @Tags         User
+// @Security BearerAuth
+// @Param 		 kind path string true "blocks или mutes" Enums(blocks, mutes)
+// @Param 		 login query string true "Логин пользователя"
+// @Success      204
+// @Router       /user-profile/{kind} [delete]
+func (a *App) RemoveRelation(w http.ResponseWriter, r *http.Request) {
+	a.proxyRelation(w, r, http.MethodDelete)
+}
+
+func (a *App) proxyRelation(w http.ResponseWriter, r *http.Request, method string) {
+	logger.InfoContext(r.Context(), method+" "+r.URL.Path)
+
+	if r.Method != method {
+		logger.ErrorContext(r.Context(), method+" "+r.URL.Path+": method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
 	}
 
-	posts, err := a.grpcClient.GetAllPostsPaginated(ctx, &pagination)
+	err := a.JWTTokenVerify(r)
 	if err != nil {
-		logger.Error(fmt.Sprintf("Get all posts failed: %v", err))
-		w.WriteHeader(http.StatusInternalServerError)
+		writeAuthError(w, err)
+		return
+	}
+
+	a.proxy.ServeHTTP(w, r)
+}
+
+// RequestPasswordReset godoc
+// @Summary      Запросить сброс пароля
+// @Description  Отправить письмо со ссылкой для сброса пароля
+// @Tags         Auth
+// @Accept		 json
+// @Produce      json
+// @Param 		 request body models.PasswordResetRequestModel true "Email пользователя"
+// @Success      202
+// @Router       /password-reset/request [post]
+func (a *App) RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /password-reset/request")
+
+	if r.Method != http.MethodPost {
+		logger.ErrorContext(r.Context(), "POST /password-reset/request: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	a.proxy.ServeHTTP(w, r)
+}
+
+// ConfirmPasswordReset godoc
+// @Summary      Сбросить пароль
+// @Description  Установить новый пароль по токену из письма. Ссылка из письма открывает страницу /reset-password фронтенда (APP_BASE_URL), которая запрашивает новый пароль и отправляет этот запрос. Снимает блокировку входа и отзывает все выданные ранее токены
+// @Tags         Auth
+// @Accept		 json
+// @Produce      json
+// @Param 		 confirm body models.PasswordResetConfirmModel true "Токен и новый пароль"
+// @Success      200
+// @Router       /password-reset/confirm [post]
+func (a *App) ConfirmPasswordReset(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /password-reset/confirm")
+
+	if r.Method != http.MethodPost {
+		logger.ErrorContext(r.Context(), "POST /password-reset/confirm: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	a.proxy.ServeHTTP(w, r)
+}
+
+// UnlockUser godoc
+// @Summary      Снять блокировку входа
+// @Description  Сбросить неудачные попытки входа и снять блокировку учетной записи
+// @Tags         Admin
+// @Accept		 json
+// @Security BearerAuth
+// @Produce      json
+// @Param 		 request body models.AdminUserModel true "Логин пользователя"
+// @Success      200
+// @Router       /admin/unlock [post]
+func (a *App) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /admin/unlock")
+
+	if r.Method != http.MethodPost {
+		logger.ErrorContext(r.Context(), "POST /admin/unlock: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	a.proxy.ServeHTTP(w, r)
+}
+
+// ChangeUserRole godoc
+// @Summary      Изменить роль
+// @Description  Назначить пользователю роль user, moderator или admin
+// @Tags         Admin
+// @Accept		 json
+// @Security BearerAuth
+// @Produce      json
+// @Param 		 request body models.RoleChangeModel true "Логин и новая роль"
+// @Success      200
+// @Router       /admin/role [put]
+func (a *App) ChangeUserRole(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "PUT /admin/role")
+
+	if r.Method != http.MethodPut {
+		logger.ErrorContext(r.Context(), "PUT /admin/role: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	a.proxy.ServeHTTP(w, r)
+}
+
+// SearchUsers godoc
+// @Summary      Список пользователей
+// @Description  Найти пользователей по логину или email
+// @Tags         Admin
+// @Security BearerAuth
+// @Produce      json
+// @Param 		 query query string false "Часть логина или email"
+// @Param 		 limit query int false "Размер страницы, до 100"
+// @Param 		 offset query int false "Смещение"
+// @Success      200  {object} models.UserPageModel
+// @Router       /admin/users [get]
+func (a *App) SearchUsers(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "GET /admin/users")
+
+	if r.Method != http.MethodGet {
+		logger.ErrorContext(r.Context(), "GET /admin/users: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	a.proxy.ServeHTTP(w, r)
+}
+
+// DeleteUser godoc
+// @Summary      Удалить пользователя
+// @Description  Удалить учетную запись пользователя
+// @Tags         Admin
+// @Security BearerAuth
+// @Param 		 login query string true "Логин пользователя"
+// @Success      204
+// @Router       /admin/users [delete]
+func (a *App) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "DELETE /admin/users")
+
+	if r.Method != http.MethodDelete {
+		logger.ErrorContext(r.Context(), "DELETE /admin/users: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	a.proxy.ServeHTTP(w, r)
+}
+
+// SuspendUser godoc
+// @Summary      Заблокировать пользователя
+// @Description  Запретить пользователю вход и доступ к API
+// @Tags         Admin
+// @Accept		 json
+// @Security BearerAuth
+// @Produce      json
+// @Param 		 request body models.SuspendModel true "Логин и причина"
+// @Success      200
+// @Router       /admin/users/suspend [post]
+func (a *App) SuspendUser(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /admin/users/suspend")
+
+	if r.Method != http.MethodPost {
+		logger.ErrorContext(r.Context(), "POST /admin/users/suspend: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	a.proxy.ServeHTTP(w, r)
+}
+
+// UnsuspendUser godoc
+// @Summary      Разблокировать пользователя
+// @Description  Вернуть пользователю доступ
+// @Tags         Admin
+// @Accept		 json
+// @Security BearerAuth
+// @Produce      json
+// @Param 		 request body models.AdminUserModel true "Логин пользователя"
+// @Success      200
+// @Router       /admin/users/unsuspend [post]
+func (a *App) UnsuspendUser(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /admin/users/unsuspend")
+
+	if r.Method != http.MethodPost {
+		logger.ErrorContext(r.Context(), "POST /admin/users/unsuspend: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	a.proxy.ServeHTTP(w, r)
+}
+
+// ForceLogout godoc
+// @Summary      Завершить сессии
+// @Description  Отозвать все выданные пользователю токены
+// @Tags         Admin
+// @Accept		 json
+// @Security BearerAuth
+// @Produce      json
+// @Param 		 request body models.AdminUserModel true "Логин пользователя"
+// @Success      200
+// @Router       /admin/users/logout [post]
+func (a *App) ForceLogout(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /admin/users/logout")
+
+	if r.Method != http.MethodPost {
+		logger.ErrorContext(r.Context(), "POST /admin/users/logout: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	a.proxy.ServeHTTP(w, r)
+}
+
+// RequeueOutbox godoc
+// @Summary      Повторить доставку событий
+// @Description  Вернуть в очередь события outbox, отложенные после слишком многих неудачных попыток доставки, например удаление контента удаленного пользователя
+// @Tags         Admin
+// @Security BearerAuth
+// @Produce      json
+// @Success      200  {object} models.RequeuedModel
+// @Router       /admin/outbox/requeue [post]
+func (a *App) RequeueOutbox(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /admin/outbox/requeue")
+
+	if r.Method != http.MethodPost {
+		logger.ErrorContext(r.Context(), "POST /admin/outbox/requeue: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	a.proxy.ServeHTTP(w, r)
+}
+
+func (a *App) CreatePost(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /post")
+	if r.Method != http.MethodPost {
+		logger.ErrorContext(r.Context(), "POST /post: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	err := a.JWTTokenVerify(r)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+
+	var post pb.PostEssential
+	data, _ := io.ReadAll(r.Body)
+	err = json.Unmarshal(data, &post)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprint(w, err.Error())
+		return
+	}
+
+	_, err = a.grpcClient.AddPost(outgoingContext(r), &post)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Add post failed", "error", err)
+		writeGrpcError(w, err)
+	}
+}
+
+func (a *App) DeletePost(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "DELETE /post")
+	if r.Method != http.MethodDelete {
+		logger.ErrorContext(r.Context(), "DELETE /post: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	err := a.JWTTokenVerify(r)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	message := pb.PostId{
+		PostId: int32(id),
+	}
+	_, err = a.grpcClient.DeletePost(outgoingContext(r), &message)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Delete post failed", "error", err)
+		writeGrpcError(w, err)
+		return
+	}
+}
+
+func (a *App) UpdatePost(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "PUT /post")
+	if r.Method != http.MethodPut {
+		logger.ErrorContext(r.Context(), "PUT /post: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	err := a.JWTTokenVerify(r)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+
+	var post pb.PostWithNoUser
+	data, _ := io.ReadAll(r.Body)
+	err = json.Unmarshal(data, &post)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	request := pb.UpdatePostRequest{
+		Id: post.Id,
+		Post: &pb.PostEssential{
+			Name:        post.Name,
+			Description: post.Description,
+			IsPrivate:   post.IsPrivate,
+			Tags:        post.Tags,
+		},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: postFields},
+	}
+	_, err = a.grpcClient.UpdatePost(outgoingContext(r), &request)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Update post failed", "error", err)
+		writeGrpcError(w, err)
+	}
+}
+
+func (a *App) PatchPost(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "PATCH /post")
+	if r.Method != http.MethodPatch {
+		logger.ErrorContext(r.Context(), "PATCH /post: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	err := a.JWTTokenVerify(r)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+
+	split := strings.Split(r.URL.Path, "/")
+	id, err := strconv.Atoi(split[len(split)-1])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	data, _ := io.ReadAll(r.Body)
+	post, mask, err := decodePostMergePatch(data)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprint(w, err.Error())
+		return
+	}
+
+	request := pb.UpdatePostRequest{
+		Id:         int32(id),
+		Post:       post,
+		UpdateMask: mask,
+	}
+	_, err = a.grpcClient.UpdatePost(outgoingContext(r), &request)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Patch post failed", "error", err)
+		writeGrpcError(w, err)
+	}
+}
+
+func (a *App) GetPostById(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "GET /get-post-by-id")
+	if r.Method != http.MethodGet {
+		logger.ErrorContext(r.Context(), "GET /get-post-by-id: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	err := a.JWTTokenVerify(r)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+
+	split := strings.Split(r.URL.Path, "/")
+	id, err := strconv.Atoi(split[len(split)-1])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	message := pb.PostId{
+		PostId: int32(id),
+	}
+	post, err := a.grpcClient.GetPostById(outgoingContext(r), &message)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Get post failed", "error", err)
+		writeGrpcError(w, err)
+		return
+	}
+
+	// a post of a user blocked either way is answered as if it did not exist
+	userId, _ := strconv.Atoi(r.Header.Get("user_id"))
+	blocked, err := a.userClient.BlockedUsers(r.Context(), userId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Get blocked users failed", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if slices.Contains(blocked, post.GetUserId()) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(a.withAuthors(r.Context(), []*pb.Post{post})[0])
+}
+
+// maxPostsPageSize bounds a page of the feed, larger limits are cut down to it.
+const maxPostsPageSize = 100
+
+func (a *App) GetPosts(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "GET /get-posts")
+	if r.Method != http.MethodGet {
+		logger.ErrorContext(r.Context(), "GET /get-posts: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	err := a.JWTTokenVerify(r)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+
+	pageSize, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || pageSize <= 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	pageSize = min(pageSize, maxPostsPageSize)
+
+	index, err := strconv.Atoi(r.URL.Query().Get("offset"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	userId, _ := strconv.Atoi(r.Header.Get("user_id"))
+	hiddenAuthors, err := a.userClient.HiddenAuthors(r.Context(), userId)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Get hidden authors failed", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	pagination := pb.Pagination{
+		PageSize:       int32(pageSize),
+		PageIndex:      int32(index),
+		ExcludeUserIds: hiddenAuthors,
+	}
+
+	posts, err := a.grpcClient.GetAllPostsPaginated(outgoingContext(r), &pagination)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Get all posts failed", "error", err)
+		writeGrpcError(w, err)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(models.PostListModel{Posts: a.withAuthors(r.Context(), posts.GetPosts())})
+}
+
+func (a *App) HidePost(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "PUT /moderation/post")
+	if r.Method != http.MethodPut {
+		logger.ErrorContext(r.Context(), "PUT /moderation/post: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	split := strings.Split(r.URL.Path, "/")
+	id, err := strconv.Atoi(split[len(split)-1])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var request pb.HidePostRequest
+	data, _ := io.ReadAll(r.Body)
+	err = json.Unmarshal(data, &request)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	request.PostId = int32(id)
+
+	_, err = a.grpcClient.HidePost(outgoingContext(r), &request)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Hide post failed", "error", err)
+		writeGrpcError(w, err)
+	}
+}
+
+func (a *App) ReportPost(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /post/report")
+	if r.Method != http.MethodPost {
+		logger.ErrorContext(r.Context(), "POST /post/report: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	err := a.JWTTokenVerify(r)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+
+	// /post/{id}/report
+	split := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(split) != 3 || split[2] != "report" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	id, err := strconv.Atoi(split[1])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var report models.ReportPostModel
+	data, _ := io.ReadAll(r.Body)
+	err = json.Unmarshal(data, &report)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	_, err = a.grpcClient.ReportPost(outgoingContext(r), &pb.ReportPostRequest{
+		PostId:  int32(id),
+		Reason:  reportReasonFromName(report.Reason),
+		Comment: report.Comment,
+	})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Report post failed", "error", err)
+		writeGrpcError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (a *App) ListReports(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "GET /moderation/reports")
+	if r.Method != http.MethodGet {
+		logger.ErrorContext(r.Context(), "GET /moderation/reports: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	request := pb.ListReportsRequest{
+		Pagination: &pb.Pagination{},
+	}
+	if status := query.Get("status"); status != "" {
+		request.Status = reportStatusFromName(status)
+		if request.Status == pb.ReportStatus_REPORT_STATUS_UNSPECIFIED {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+	if limit := query.Get("limit"); limit != "" {
+		pageSize, err := strconv.Atoi(limit)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		request.Pagination.PageSize = int32(pageSize)
+	}
+	if offset := query.Get("offset"); offset != "" {
+		index, err := strconv.Atoi(offset)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		request.Pagination.PageIndex = int32(index)
+	}
+
+	reports, err := a.grpcClient.ListReports(outgoingContext(r), &request)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "List reports failed", "error", err)
+		writeGrpcError(w, err)
+		return
+	}
+
+	list := models.ReportListModel{
+		Reports: make([]models.ReportModel, 0, len(reports.GetReports())),
+		Total:   reports.GetTotal(),
+	}
+	for _, report := range reports.GetReports() {
+		list.Reports = append(list.Reports, toReportModel(report))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(list)
+}
+
+func (a *App) UpdateReportStatus(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "PUT /moderation/reports")
+	if r.Method != http.MethodPut {
+		logger.ErrorContext(r.Context(), "PUT /moderation/reports: method not allowed")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	split := strings.Split(r.URL.Path, "/")
+	id, err := strconv.Atoi(split[len(split)-1])
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var change models.ReportStatusModel
+	data, _ := io.ReadAll(r.Body)
+	err = json.Unmarshal(data, &change)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	report, err := a.grpcClient.UpdateReportStatus(outgoingContext(r), &pb.UpdateReportStatusRequest{
+		ReportId: int32(id),
+		Status:   reportStatusFromName(change.Status),
+	})
+	if err != nil {
+		logger.ErrorContext(r.Context(), "Update report status failed", "error", err)
+		writeGrpcError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(toReportModel(report))
+}
+
+// outgoingContext passes the user authenticated by JWTTokenVerify to the
+// services, as the principal of the internal token the connections sign.
+// A request without a user goes out without one and is rejected.
+func outgoingContext(r *http.Request) context.Context {
+	principal, ok := proxy.Principal(r)
+	if !ok {
+		return r.Context()
+	}
+	return internaltoken.NewContext(r.Context(), principal)
+}
+
+// UpstreamsHealth godoc
+// @Summary      Состояние сервисов
+// @Description  Состояние соединений шлюза с внутренними сервисами
+// @Tags         Health
+// @Produce      json
+// @Success      200  {array} models.UpstreamHealth
+// @Router       /health/upstreams [get]
+func (a *App) UpstreamsHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
 
-	_ = json.NewEncoder(w).Encode(posts)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode([]models.UpstreamHealth{a.postsConn.Health()})
 }
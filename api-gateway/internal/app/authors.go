@@ -0,0 +1,36 @@
+package app
+
+import (
+	"context"
+	"social-network/api-gateway/internal/models"
+	"social-network/pkg/logger"
+	pb "social-network/protos"
+)
+
+// withAuthors embeds the authors into the posts with a single lookup for
+// all distinct authors. When user-service is unavailable the posts are
+// still returned, only without authors.
+func (a *App) withAuthors(ctx context.Context, posts []*pb.Post) []models.PostModel {
+	ids := make([]int32, 0, len(posts))
+	seen := make(map[int32]bool, len(posts))
+	for _, post := range posts {
+		if !seen[post.GetUserId()] {
+			seen[post.GetUserId()] = true
+			ids = append(ids, post.GetUserId())
+		}
+	}
+
+	authors, err := a.userClient.GetAuthors(ctx, ids)
+	if err != nil {
+		logger.ErrorContext(ctx, "Get post authors failed", "error", err)
+	}
+
+	result := make([]models.PostModel, len(posts))
+	for i, post := range posts {
+		result[i] = models.PostModel{
+			Post:   post,
+			Author: authors[post.GetUserId()],
+		}
+	}
+	return result
+}
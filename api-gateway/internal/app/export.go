@@ -0,0 +1,52 @@
+package app
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"social-network/api-gateway/internal/models"
+	pb "social-network/protos"
+)
+
+// buildExportArchive packs the user's data into a zip with one JSON file per service resource.
+func buildExportArchive(profile []byte, content *pb.UserContent) ([]byte, error) {
+	reports := make([]models.ReportModel, 0, len(content.GetReports()))
+	for _, report := range content.GetReports() {
+		reports = append(reports, toReportModel(report))
+	}
+	posts := content.GetPosts()
+	if posts == nil {
+		posts = []*pb.Post{}
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", json.RawMessage(profile)},
+		{"posts.json", posts},
+		{"reports.json", reports},
+	}
+	for _, file := range files {
+		writer, err := archive.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(file.data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := archive.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
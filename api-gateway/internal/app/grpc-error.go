@@ -0,0 +1,54 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	customErrors "social-network/api-gateway/internal/errors"
+	"social-network/pkg/validation"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func writeGrpcError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	switch st.Code() {
+	case codes.InvalidArgument:
+		for _, detail := range st.Details() {
+			if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+				writeValidationError(w, validation.FromBadRequest(badRequest))
+				return
+			}
+		}
+		w.WriteHeader(http.StatusBadRequest)
+	case codes.NotFound:
+		w.WriteHeader(http.StatusNotFound)
+	case codes.PermissionDenied:
+		w.WriteHeader(http.StatusForbidden)
+	case codes.AlreadyExists:
+		w.WriteHeader(http.StatusConflict)
+	case codes.Unauthenticated:
+		w.WriteHeader(http.StatusUnauthorized)
+	case codes.Unavailable:
+		w.WriteHeader(http.StatusServiceUnavailable)
+	case codes.DeadlineExceeded:
+		w.WriteHeader(http.StatusGatewayTimeout)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	_, _ = fmt.Fprint(w, st.Message())
+}
+
+func writeValidationError(w http.ResponseWriter, err *customErrors.ValidationError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(struct {
+		Message string                    `json:"message"`
+		Fields  []customErrors.FieldError `json:"fields"`
+	}{
+		Message: err.Error(),
+		Fields:  err.Fields,
+	})
+}
@@ -1,12 +1,15 @@
 package app
 
 import (
+	"errors"
 	"fmt"
 	"github.com/golang-jwt/jwt/v5"
 	"net/http"
 	"os"
+	"slices"
 	customErros "social-network/api-gateway/internal/errors"
-	"social-network/api-gateway/internal/logger"
+	"social-network/api-gateway/internal/models"
+	"social-network/pkg/logger"
 	"strconv"
 )
 
@@ -14,16 +17,106 @@ type Claims struct {
 	Login string `json:"login"`
 	Name  string `json:"name"`
 	Id    int    `json:"user-id"`
+	Role  string `json:"role"`
+	// TokenVersion is bumped by user-service to revoke every token issued before.
+	TokenVersion int `json:"token-version"`
 	jwt.RegisteredClaims
 }
 
-func JWTTokenVerify(r *http.Request) error {
+// JWTTokenVerify checks the token and that its owner still exists, is not
+// suspended and has not been logged out since, then passes the caller to
+// the services in headers. The role comes from user-service rather than
+// the token, so a role change applies without a new login.
+func (a *App) JWTTokenVerify(r *http.Request) error {
 	tokenString := r.Header.Get("Authorization")
 	if tokenString == "" {
-		logger.Error("token is empty")
+		logger.ErrorContext(r.Context(), "token is empty")
 		return &customErros.JWTTokenEmpty{}
 	}
 
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "token is invalid")
+		return err
+	}
+	if claims.Role == "" {
+		claims.Role = models.RoleUser
+	}
+
+	status, err := a.userClient.GetUserStatus(r.Context(), claims.Id)
+	var notFoundErr *customErros.UserNotFound
+	switch {
+	case errors.As(err, &notFoundErr):
+		logger.ErrorContext(r.Context(), "token of deleted user", "user_id", claims.Id)
+		return &customErros.JWTTokenInvalid{}
+	case err != nil:
+		// a token is only as good as the status behind it: without one, a
+		// revoked token or a suspended or demoted user would get through
+		logger.ErrorContext(r.Context(), "failed to get user status", "user_id", claims.Id, "error", err)
+		return &customErros.StatusUnavailable{}
+	case status.Suspended:
+		return &customErros.AccountSuspended{Reason: status.SuspendReason}
+	case claims.TokenVersion < status.TokenVersion:
+		logger.ErrorContext(r.Context(), "revoked token", "user_id", claims.Id)
+		return &customErros.TokenRevoked{}
+	default:
+		claims.Role = status.Role
+	}
+
+	r.Header.Set("login", claims.Login)
+	r.Header.Set("name", claims.Name)
+	r.Header.Set("user_id", strconv.Itoa(claims.Id))
+	r.Header.Set("role", claims.Role)
+
+	return nil
+}
+
+// writeAuthError answers 403 to suspended users, 503 when the token could not
+// be checked and 401 to everything else.
+func writeAuthError(w http.ResponseWriter, err error) {
+	var suspendedErr *customErros.AccountSuspended
+	var unavailableErr *customErros.StatusUnavailable
+	switch {
+	case errors.As(err, &suspendedErr):
+		w.WriteHeader(http.StatusForbidden)
+	case errors.As(err, &unavailableErr):
+		w.WriteHeader(http.StatusServiceUnavailable)
+	default:
+		w.WriteHeader(http.StatusUnauthorized)
+	}
+	_, _ = fmt.Fprint(w, err.Error())
+}
+
+// RequireRole lets the request through only when its token has one of the roles.
+func (a *App) RequireRole(next http.HandlerFunc, roles ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := a.JWTTokenVerify(r)
+		if err != nil {
+			writeAuthError(w, err)
+			return
+		}
+
+		if !slices.Contains(roles, r.Header.Get("role")) {
+			logger.WarnContext(r.Context(), "role is not allowed", "method", r.Method, "path", r.URL.Path, "role", r.Header.Get("role"))
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = fmt.Fprint(w, (&customErros.PermissionDenied{}).Error())
+			return
+		}
+		next(w, r)
+	}
+}
+
+// RequesterKey identifies the caller for rate limiting: by user id when
+// the request carries a valid token, by IP address otherwise.
+func (a *App) RequesterKey(r *http.Request) string {
+	claims, err := parseToken(r.Header.Get("Authorization"))
+	if err != nil {
+		return "ip:" + clientIP(r)
+	}
+	return "user:" + strconv.Itoa(claims.Id)
+}
+
+func parseToken(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
@@ -34,13 +127,7 @@ func JWTTokenVerify(r *http.Request) error {
 	})
 
 	if err != nil || !token.Valid {
-		logger.Error("token is invalid")
-		return &customErros.JWTTokenInvalid{}
+		return nil, &customErros.JWTTokenInvalid{}
 	}
-
-	r.Header.Set("login", claims.Login)
-	r.Header.Set("name", claims.Name)
-	r.Header.Set("user_id", strconv.Itoa(claims.Id))
-
-	return nil
+	return claims, nil
 }
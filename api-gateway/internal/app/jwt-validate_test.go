@@ -0,0 +1,57 @@
+package app
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"social-network/api-gateway/internal/client"
+	"social-network/api-gateway/internal/config"
+	pb "social-network/protos"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+)
+
+type fakeStatuses struct {
+	pb.UserServiceClient
+	status *pb.UserStatus
+}
+
+func (f *fakeStatuses) GetUserStatus(context.Context, *pb.GetUserStatusRequest, ...grpc.CallOption) (*pb.UserStatus, error) {
+	return f.status, nil
+}
+
+// TestJWTTokenVerifySetsOnlyTrustedHeaders guards the list of headers the
+// gateway drops from client requests: a header JWTTokenVerify sets but the
+// gateway doesn't drop could be sent by a client to the services.
+func TestJWTTokenVerifySetsOnlyTrustedHeaders(t *testing.T) {
+	t.Setenv("SECRET_KEY", "test-secret")
+	cfg := config.NewConfig()
+
+	users := &fakeStatuses{status: &pb.UserStatus{Id: 7, Role: "user"}}
+	a := &App{userClient: client.NewUserServiceClient(&config.Config{UserServiceTimeout: time.Second}, users, nil)}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{Login: "alice", Name: "Alice", Id: 7}).SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest(http.MethodGet, "/user-profile", nil)
+	r.Header.Set("Authorization", token)
+
+	err = a.JWTTokenVerify(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trusted := make(map[string]bool, len(cfg.Proxy.TrustedHeaders))
+	for _, header := range cfg.Proxy.TrustedHeaders {
+		trusted[http.CanonicalHeaderKey(header)] = true
+	}
+	for header := range r.Header {
+		if header != "Authorization" && !trusted[header] {
+			t.Errorf("JWTTokenVerify sets %s, which is not in Proxy.TrustedHeaders", header)
+		}
+	}
+}
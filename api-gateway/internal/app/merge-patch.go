@@ -0,0 +1,45 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	pb "social-network/protos"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+var postFields = []string{"name", "description", "is_private", "tags"}
+
+// decodePostMergePatch parses a JSON Merge Patch (RFC 7396) document into a post
+// and a field mask with every member that was sent. A null member clears the field.
+func decodePostMergePatch(data []byte) (*pb.PostEssential, *fieldmaskpb.FieldMask, error) {
+	var patch map[string]json.RawMessage
+	if err := json.Unmarshal(data, &patch); err != nil || patch == nil {
+		return nil, nil, fmt.Errorf("patch must be a JSON object")
+	}
+	if len(patch) == 0 {
+		return nil, nil, fmt.Errorf("patch is empty")
+	}
+
+	mask := &fieldmaskpb.FieldMask{}
+	values := make(map[string]json.RawMessage)
+	for field, value := range patch {
+		if !slices.Contains(postFields, field) {
+			return nil, nil, fmt.Errorf("unknown field: %s", field)
+		}
+		mask.Paths = append(mask.Paths, field)
+		if string(value) != "null" {
+			values[field] = value
+		}
+	}
+
+	post := &pb.PostEssential{}
+	data, _ = json.Marshal(values)
+	if err := json.Unmarshal(data, post); err != nil {
+		return nil, nil, fmt.Errorf("invalid field value: %v", err)
+	}
+	mask.Normalize()
+
+	return post, mask, nil
+}
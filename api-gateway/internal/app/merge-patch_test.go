@@ -0,0 +1,86 @@
+package app
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDecodePostMergePatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantErr   bool
+		wantPaths []string
+		wantName  string
+		wantTags  []string
+	}{
+		{
+			name:      "only the sent fields go into the mask",
+			body:      `{"name":"new name"}`,
+			wantPaths: []string{"name"},
+			wantName:  "new name",
+		},
+		{
+			name:      "null clears the field",
+			body:      `{"description":null,"tags":null}`,
+			wantPaths: []string{"description", "tags"},
+		},
+		{
+			name:      "null and values together",
+			body:      `{"name":"post","tags":["go"],"is_private":null}`,
+			wantPaths: []string{"is_private", "name", "tags"},
+			wantName:  "post",
+			wantTags:  []string{"go"},
+		},
+		{
+			name:    "unknown field",
+			body:    `{"name":"post","creator_id":1}`,
+			wantErr: true,
+		},
+		{
+			name:    "empty patch",
+			body:    `{}`,
+			wantErr: true,
+		},
+		{
+			name:    "null document",
+			body:    `null`,
+			wantErr: true,
+		},
+		{
+			name:    "array instead of an object",
+			body:    `["name"]`,
+			wantErr: true,
+		},
+		{
+			name:    "wrong value type",
+			body:    `{"is_private":"yes"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post, mask, err := decodePostMergePatch([]byte(tt.body))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got mask %v", mask.GetPaths())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(mask.GetPaths(), tt.wantPaths) {
+				t.Errorf("mask = %v, want %v", mask.GetPaths(), tt.wantPaths)
+			}
+			if post.GetName() != tt.wantName {
+				t.Errorf("name = %q, want %q", post.GetName(), tt.wantName)
+			}
+			if !slices.Equal(post.GetTags(), tt.wantTags) {
+				t.Errorf("tags = %v, want %v", post.GetTags(), tt.wantTags)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package app
+
+import (
+	"social-network/api-gateway/internal/models"
+	pb "social-network/protos"
+	"strings"
+)
+
+// Report reasons and statuses travel as proto enums and are shown to
+// clients as lowercase names without the enum prefix: "hate_speech".
+
+func reportReasonFromName(name string) pb.ReportReason {
+	return pb.ReportReason(pb.ReportReason_value["REPORT_REASON_"+strings.ToUpper(name)])
+}
+
+func reportStatusFromName(name string) pb.ReportStatus {
+	return pb.ReportStatus(pb.ReportStatus_value["REPORT_STATUS_"+strings.ToUpper(name)])
+}
+
+func toReportModel(report *pb.Report) models.ReportModel {
+	return models.ReportModel{
+		Id:         report.GetId(),
+		PostId:     report.GetPostId(),
+		ReporterId: report.GetReporterId(),
+		Reason:     strings.ToLower(strings.TrimPrefix(report.GetReason().String(), "REPORT_REASON_")),
+		Comment:    report.GetComment(),
+		Status:     strings.ToLower(strings.TrimPrefix(report.GetStatus().String(), "REPORT_STATUS_")),
+		ReviewerId: report.GetReviewerId(),
+		CreatedAt:  report.GetCreatedAt().AsTime(),
+		UpdatedAt:  report.GetUpdatedAt().AsTime(),
+	}
+}
@@ -0,0 +1,127 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"social-network/api-gateway/internal/models"
+	pb "social-network/protos"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+var (
+	profileFields    = []string{"name", "family_name", "phone", "bio", "birthday", "interests", "address", "private_info"}
+	credentialFields = []string{"login", "password", "email"}
+)
+
+const updateCredentialsMessage = "You can't update credentials"
+
+func toUserModel(user *pb.User) models.UserModel {
+	privacy := user.GetPrivacy()
+	return models.UserModel{
+		Id:            int(user.GetId()),
+		Name:          user.GetName(),
+		FamilyName:    user.GetFamilyName(),
+		Login:         user.GetLogin(),
+		Email:         user.GetEmail(),
+		Phone:         user.GetPhone(),
+		Avatar:        user.GetAvatar(),
+		Bio:           user.GetBio(),
+		Birthday:      user.GetBirthday(),
+		Interests:     user.GetInterests(),
+		Address:       user.GetAddress(),
+		PrivateInfo:   user.GetPrivateInfo(),
+		EmailVerified: user.GetEmailVerified(),
+		Role:          user.GetRole(),
+		RegisteredAt:  user.GetRegisteredAt().AsTime(),
+		UpdatedAt:     user.GetUpdatedAt().AsTime(),
+		Privacy: models.PrivacyModel{
+			Name:         privacy.GetName(),
+			FamilyName:   privacy.GetFamilyName(),
+			Avatar:       privacy.GetAvatar(),
+			Bio:          privacy.GetBio(),
+			Birthday:     privacy.GetBirthday(),
+			Interests:    privacy.GetInterests(),
+			RegisteredAt: privacy.GetRegisteredAt(),
+			PostsCount:   privacy.GetPostsCount(),
+		},
+	}
+}
+
+// profileFromUserModel takes the profile fields of a full profile update,
+// the read-only fields of the model are ignored.
+func profileFromUserModel(user *models.UserModel) *pb.User {
+	return &pb.User{
+		Name:        user.Name,
+		FamilyName:  user.FamilyName,
+		Phone:       user.Phone,
+		Bio:         user.Bio,
+		Birthday:    user.Birthday,
+		Interests:   user.Interests,
+		Address:     user.Address,
+		PrivateInfo: user.PrivateInfo,
+	}
+}
+
+// decodeProfileMergePatch parses a JSON Merge Patch for the user profile into
+// the profile fields and a mask with every member sent. A null member clears the field.
+func decodeProfileMergePatch(data []byte) (*pb.User, *fieldmaskpb.FieldMask, error) {
+	var patch map[string]json.RawMessage
+	if err := json.Unmarshal(data, &patch); err != nil || patch == nil {
+		return nil, nil, fmt.Errorf("patch must be a JSON object")
+	}
+
+	mask := &fieldmaskpb.FieldMask{}
+	values := make(map[string]json.RawMessage)
+	for field, value := range patch {
+		if slices.Contains(credentialFields, field) {
+			return nil, nil, errors.New(updateCredentialsMessage)
+		}
+		if !slices.Contains(profileFields, field) {
+			return nil, nil, fmt.Errorf("Unknown field: %s", field)
+		}
+		mask.Paths = append(mask.Paths, field)
+		if string(value) != "null" {
+			values[field] = value
+		}
+	}
+
+	user := &pb.User{}
+	data, _ = json.Marshal(values)
+	if err := json.Unmarshal(data, user); err != nil {
+		return nil, nil, fmt.Errorf("invalid field value: %v", err)
+	}
+	mask.Normalize()
+
+	return user, mask, nil
+}
+
+// writeCredentialsError answers register and login failures the way user-service
+// did over HTTP: bad credentials and taken logins are plain 400 responses.
+func writeCredentialsError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	switch st.Code() {
+	case codes.NotFound, codes.AlreadyExists:
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprint(w, st.Message())
+	case codes.ResourceExhausted:
+		for _, detail := range st.Details() {
+			if retryInfo, ok := detail.(*errdetails.RetryInfo); ok {
+				retryAfter := retryInfo.GetRetryDelay().AsDuration()
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			}
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = fmt.Fprint(w, st.Message())
+	default:
+		writeGrpcError(w, err)
+	}
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDecodeProfileMergePatch(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		wantErr       string
+		wantPaths     []string
+		wantBio       string
+		wantInterests []string
+	}{
+		{
+			name:      "only the sent fields go into the mask",
+			body:      `{"bio":"hello"}`,
+			wantPaths: []string{"bio"},
+			wantBio:   "hello",
+		},
+		{
+			name:      "null clears the field",
+			body:      `{"phone":null,"interests":null}`,
+			wantPaths: []string{"interests", "phone"},
+		},
+		{
+			name:          "null and values together",
+			body:          `{"bio":"hello","interests":["go"],"address":null}`,
+			wantPaths:     []string{"address", "bio", "interests"},
+			wantBio:       "hello",
+			wantInterests: []string{"go"},
+		},
+		{
+			name: "empty patch changes nothing",
+			body: `{}`,
+		},
+		{
+			name:    "credentials",
+			body:    `{"bio":"hello","password":"secret"}`,
+			wantErr: updateCredentialsMessage,
+		},
+		{
+			name:    "unknown field",
+			body:    `{"role":"admin"}`,
+			wantErr: "Unknown field: role",
+		},
+		{
+			name:    "null document",
+			body:    `null`,
+			wantErr: "patch must be a JSON object",
+		},
+		{
+			name:    "wrong value type",
+			body:    `{"interests":"go"}`,
+			wantErr: "invalid field value",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, mask, err := decodeProfileMergePatch([]byte(tt.body))
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(mask.GetPaths(), tt.wantPaths) {
+				t.Errorf("mask = %v, want %v", mask.GetPaths(), tt.wantPaths)
+			}
+			if user.GetBio() != tt.wantBio {
+				t.Errorf("bio = %q, want %q", user.GetBio(), tt.wantBio)
+			}
+			if !slices.Equal(user.GetInterests(), tt.wantInterests) {
+				t.Errorf("interests = %v, want %v", user.GetInterests(), tt.wantInterests)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// call sends one call through the breaker to an upstream answering with code
+// and tells whether the upstream was reached.
+func call(b *Breaker, code codes.Code) (bool, error) {
+	reached := false
+	invoker := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+		reached = true
+		return status.Error(code, code.String())
+	}
+	err := b.UnaryClientInterceptor("posts-service")(context.Background(), "/PostsService/GetPostById", nil, nil, nil, invoker)
+	return reached, err
+}
+
+// expire makes the open breaker ready to let a probe through.
+func expire(b *Breaker) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.openedAt = time.Now().Add(-b.openTimeout)
+}
+
+func TestBreakerOpensAfterThreshold(t *testing.T) {
+	b := NewBreaker(3, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		_, _ = call(b, codes.Unavailable)
+	}
+	if b.State() != BreakerClosed {
+		t.Fatalf("state = %s below the threshold", b.State())
+	}
+
+	_, _ = call(b, codes.DeadlineExceeded)
+	if b.State() != BreakerOpen {
+		t.Fatalf("state = %s, want open", b.State())
+	}
+
+	reached, err := call(b, codes.OK)
+	if reached || status.Code(err) != codes.Unavailable {
+		t.Errorf("open breaker: reached = %v, error = %v, want rejected with Unavailable", reached, err)
+	}
+}
+
+func TestBreakerIgnoresDeliberateErrors(t *testing.T) {
+	b := NewBreaker(2, time.Minute)
+
+	for _, code := range []codes.Code{codes.NotFound, codes.PermissionDenied, codes.InvalidArgument, codes.NotFound} {
+		_, _ = call(b, code)
+	}
+	if b.State() != BreakerClosed {
+		t.Errorf("state = %s, want errors returned on purpose not to count", b.State())
+	}
+}
+
+func TestBreakerSuccessResetsFailures(t *testing.T) {
+	b := NewBreaker(2, time.Minute)
+
+	_, _ = call(b, codes.Unavailable)
+	_, _ = call(b, codes.OK)
+	_, _ = call(b, codes.Unavailable)
+	if b.State() != BreakerClosed {
+		t.Errorf("state = %s, want only consecutive failures to count", b.State())
+	}
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	tests := []struct {
+		name      string
+		probe     codes.Code
+		wantState string
+	}{
+		{name: "successful probe closes", probe: codes.OK, wantState: BreakerClosed},
+		{name: "deliberate error closes", probe: codes.NotFound, wantState: BreakerClosed},
+		{name: "failed probe opens again", probe: codes.Unavailable, wantState: BreakerOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBreaker(1, time.Minute)
+			_, _ = call(b, codes.Unavailable)
+			expire(b)
+
+			if b.State() != BreakerHalfOpen {
+				t.Fatalf("state = %s after the open timeout, want half-open", b.State())
+			}
+
+			reached, _ := call(b, tt.probe)
+			if !reached {
+				t.Fatal("probe did not reach the upstream")
+			}
+			if b.State() != tt.wantState {
+				t.Errorf("state = %s, want %s", b.State(), tt.wantState)
+			}
+		})
+	}
+}
+
+func TestBreakerLetsOneProbeThrough(t *testing.T) {
+	b := NewBreaker(1, time.Minute)
+	_, _ = call(b, codes.Unavailable)
+	expire(b)
+
+	probeStarted := make(chan struct{})
+	finishProbe := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		invoker := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+			close(probeStarted)
+			<-finishProbe
+			return nil
+		}
+		_ = b.UnaryClientInterceptor("posts-service")(context.Background(), "/PostsService/GetPostById", nil, nil, nil, invoker)
+	}()
+
+	<-probeStarted
+	reached, err := call(b, codes.OK)
+	if reached || status.Code(err) != codes.Unavailable {
+		t.Errorf("second call during the probe: reached = %v, error = %v, want rejected", reached, err)
+	}
+
+	close(finishProbe)
+	<-done
+	if b.State() != BreakerClosed {
+		t.Errorf("state = %s after the probe succeeded, want closed", b.State())
+	}
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+// ttlCache keeps values for a fixed time. A nil value is cached as well so
+// that lookups of missing users are not repeated on every request.
+type ttlCache[K comparable, V any] struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	entries   map[K]cacheEntry[V]
+	lastSweep time.Time
+}
+
+type cacheEntry[V any] struct {
+	value     *V
+	expiresAt time.Time
+}
+
+func newTTLCache[K comparable, V any](ttl time.Duration) *ttlCache[K, V] {
+	return &ttlCache[K, V]{
+		ttl:     ttl,
+		entries: make(map[K]cacheEntry[V]),
+	}
+}
+
+func (c *ttlCache[K, V]) get(key K, now time.Time) (*V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || !now.Before(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (c *ttlCache[K, V]) set(key K, value *V, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = cacheEntry[V]{value: value, expiresAt: now.Add(c.ttl)}
+	c.sweep(now)
+}
+
+// sweep drops expired entries so the cache doesn't grow unbounded, called with mu held.
+func (c *ttlCache[K, V]) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < c.ttl {
+		return
+	}
+	c.lastSweep = now
+
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -1,31 +1,123 @@
 package client
 
 import (
+	"context"
 	"fmt"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
+	"go.uber.org/fx"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
-	"os"
-	"os/signal"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"social-network/api-gateway/internal/config"
-	"social-network/api-gateway/internal/logger"
+	"social-network/api-gateway/internal/models"
+	"social-network/pkg/certs"
+	"social-network/pkg/internaltoken"
+	"social-network/pkg/logger"
+	"social-network/pkg/metrics"
+	"social-network/pkg/requestid"
 	pb "social-network/protos"
-	"syscall"
+	"time"
 )
 
-func NewGrpcConnection(cfg *config.Config) (pb.PostsServiceClient, error) {
-	conn, err := grpc.NewClient("posts-service"+cfg.GrpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// postsServiceConfig retries the reads of posts-service when it is briefly
+// unavailable, writes are never retried as they are not idempotent.
+const postsServiceConfig = `{
+	"methodConfig": [{
+		"name": [
+			{"service": "PostsService", "method": "GetPostById"},
+			{"service": "PostsService", "method": "GetAllPostsPaginated"},
+			{"service": "PostsService", "method": "ListReports"},
+			{"service": "PostsService", "method": "ExportUserContent"},
+			{"service": "PostsService", "method": "GetUserStats"}
+		],
+		"retryPolicy": {
+			"maxAttempts": %d,
+			"initialBackoff": "0.05s",
+			"maxBackoff": "0.5s",
+			"backoffMultiplier": 2,
+			"retryableStatusCodes": ["UNAVAILABLE"]
+		}
+	}]
+}`
+
+// PostsConnection is the connection to posts-service shared by all handlers.
+type PostsConnection struct {
+	conn    *grpc.ClientConn
+	breaker *Breaker
+}
+
+func NewPostsConnection(lc fx.Lifecycle, cfg *config.Config, internal *certs.Reloader) (*PostsConnection, error) {
+	breaker := NewBreaker(cfg.PostsBreakerThreshold, cfg.PostsBreakerOpenTimeout)
+	signer, err := internaltoken.NewSigner(cfg.InternalTokenKey, "api-gateway", "posts-service")
 	if err != nil {
-		logger.Error(fmt.Sprintf("error connecting to grpc server: %v", err))
+		logger.Error("error creating internal token signer", "error", err)
 		return nil, err
 	}
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	conn, err := grpc.NewClient(
+		cfg.PostsGrpcAddr,
+		grpc.WithTransportCredentials(certs.ClientCredentials(internal)),
+		grpc.WithDefaultServiceConfig(fmt.Sprintf(postsServiceConfig, cfg.PostsMaxAttempts)),
+		// readiness probes would otherwise start a trace every few seconds
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler(otelgrpc.WithFilter(filters.Not(filters.HealthCheck())))),
+		// the breaker sees the outcome of a call after all of its retries
+		grpc.WithChainUnaryInterceptor(
+			metrics.UnaryClientInterceptor(),
+			requestid.UnaryClientInterceptor(),
+			signer.UnaryClientInterceptor(),
+			breaker.UnaryClientInterceptor("posts-service"),
+			deadlineInterceptor(cfg.PostsCallTimeout),
+		),
+	)
+	if err != nil {
+		logger.Error("error connecting to grpc server", "error", err)
+		return nil, err
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(_ context.Context) error {
+			return conn.Close()
+		},
+	})
+	return &PostsConnection{
+		conn:    conn,
+		breaker: breaker,
+	}, nil
+}
+
+func NewPostsClient(pc *PostsConnection) pb.PostsServiceClient {
+	return pb.NewPostsServiceClient(pc.conn)
+}
 
-	go func() {
-		<-sigChan
-		conn.Close()
-	}()
+func (pc *PostsConnection) Health() models.UpstreamHealth {
+	return models.UpstreamHealth{
+		Name:       "posts-service",
+		Connection: pc.conn.GetState().String(),
+		Breaker:    pc.breaker.State(),
+	}
+}
 
-	return pb.NewPostsServiceClient(conn), nil
+// Check asks posts-service through the standard gRPC health service whether it can serve requests.
+func (pc *PostsConnection) Check(ctx context.Context) error {
+	resp, err := healthpb.NewHealthClient(pc.conn).Check(ctx, &healthpb.HealthCheckRequest{Service: "PostsService"})
+	if err != nil {
+		return err
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("posts-service is %s", resp.GetStatus())
+	}
+	return nil
+}
+
+// deadlineInterceptor bounds calls whose context has no deadline of its own,
+// the context of an HTTP request is cancelled only when the client goes away.
+func deadlineInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
 }
@@ -0,0 +1,190 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"slices"
+	"social-network/api-gateway/internal/config"
+	customErrors "social-network/api-gateway/internal/errors"
+	"social-network/api-gateway/internal/models"
+	"social-network/pkg/certs"
+	"social-network/pkg/requestid"
+	pb "social-network/protos"
+	"time"
+)
+
+// UserServiceClient calls the internal RPCs of user-service. User
+// statuses and post authors are cached for a short time so that a burst
+// of requests costs a single lookup.
+type UserServiceClient struct {
+	baseURL    string
+	httpClient *http.Client
+	users      pb.UserServiceClient
+	timeout    time.Duration
+
+	statuses *ttlCache[int, models.UserStatus]
+	authors  *ttlCache[int32, models.AuthorModel]
+}
+
+func NewUserServiceClient(cfg *config.Config, users pb.UserServiceClient, internal *certs.Reloader) *UserServiceClient {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = certs.ClientTLS(internal)
+	httpClient := &http.Client{
+		Timeout:   cfg.UserServiceTimeout,
+		Transport: otelhttp.NewTransport(&requestid.Transport{Base: transport}),
+	}
+	return &UserServiceClient{
+		baseURL:    certs.Scheme(internal) + "://" + cfg.UserServiceAddr,
+		httpClient: httpClient,
+		users:      users,
+		timeout:    cfg.UserServiceTimeout,
+		statuses:   newTTLCache[int, models.UserStatus](cfg.UserStatusCacheTTL),
+		authors:    newTTLCache[int32, models.AuthorModel](cfg.AuthorCacheTTL),
+	}
+}
+
+// GetUserStatus tells whether a token owner still exists, is not suspended
+// and what role they have now. It returns UserNotFound when the user has been deleted.
+func (c *UserServiceClient) GetUserStatus(ctx context.Context, id int) (*models.UserStatus, error) {
+	now := time.Now()
+
+	status, ok := c.statuses.get(id, now)
+	if !ok {
+		var err error
+		status, err = c.fetchStatus(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		c.statuses.set(id, status, now)
+	}
+
+	if status == nil {
+		return nil, &customErrors.UserNotFound{}
+	}
+	return status, nil
+}
+
+func (c *UserServiceClient) fetchStatus(ctx context.Context, id int) (*models.UserStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.users.GetUserStatus(ServiceContext(ctx), &pb.GetUserStatusRequest{Id: int32(id)})
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &models.UserStatus{
+		Id:            int(resp.GetId()),
+		Role:          resp.GetRole(),
+		Suspended:     resp.GetSuspended(),
+		SuspendReason: resp.GetSuspendReason(),
+		TokenVersion:  int(resp.GetTokenVersion()),
+	}, nil
+}
+
+// Ping checks that user-service is up.
+func (c *UserServiceClient) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/healthz", nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("user-service health: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// HiddenAuthors returns the users whose posts the user must not see because of blocks and mutes.
+func (c *UserServiceClient) HiddenAuthors(ctx context.Context, userId int) ([]int32, error) {
+	return c.hiddenAuthors(ctx, &pb.GetHiddenAuthorsRequest{UserId: int32(userId)})
+}
+
+// BlockedUsers returns the users the user blocked or was blocked by. Unlike
+// the muted ones their posts stay hidden when opened directly.
+func (c *UserServiceClient) BlockedUsers(ctx context.Context, userId int) ([]int32, error) {
+	return c.hiddenAuthors(ctx, &pb.GetHiddenAuthorsRequest{UserId: int32(userId), BlocksOnly: true})
+}
+
+func (c *UserServiceClient) hiddenAuthors(ctx context.Context, req *pb.GetHiddenAuthorsRequest) ([]int32, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.users.GetHiddenAuthors(ServiceContext(ctx), req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetIds(), nil
+}
+
+// maxAuthorsBatch is the most users user-service resolves in one BatchGetUsers call.
+const maxAuthorsBatch = 100
+
+// GetAuthors resolves the given users to the author info shown next to their posts,
+// in batches as large as user-service accepts. Users that don't exist or whose
+// profiles are hidden are missing from the result.
+func (c *UserServiceClient) GetAuthors(ctx context.Context, ids []int32) (map[int32]*models.AuthorModel, error) {
+	now := time.Now()
+
+	authors := make(map[int32]*models.AuthorModel, len(ids))
+	var missing []int32
+	for _, id := range ids {
+		if _, ok := authors[id]; ok {
+			continue
+		}
+		author, ok := c.authors.get(id, now)
+		if !ok {
+			missing = append(missing, id)
+		}
+		authors[id] = author
+	}
+	if len(missing) == 0 {
+		return authors, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	for batch := range slices.Chunk(missing, maxAuthorsBatch) {
+		resp, err := c.users.BatchGetUsers(ServiceContext(ctx), &pb.BatchGetUsersRequest{Ids: batch})
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range resp.GetUsers() {
+			authors[user.GetId()] = &models.AuthorModel{
+				Id:          user.GetId(),
+				Login:       user.GetLogin(),
+				DisplayName: user.GetDisplayName(),
+				Avatar:      user.GetAvatar(),
+			}
+		}
+	}
+	for _, id := range missing {
+		c.authors.set(id, authors[id], now)
+	}
+	return authors, nil
+}
+
+// ExportUser returns the JSON document user-service builds for a data export.
+func (c *UserServiceClient) ExportUser(ctx context.Context, login string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.users.ExportUser(ServiceContext(ctx), &pb.ExportUserRequest{Login: login})
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetDocument(), nil
+}
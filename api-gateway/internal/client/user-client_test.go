@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"slices"
+	"social-network/api-gateway/internal/config"
+	pb "social-network/protos"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeUsers answers BatchGetUsers like user-service, rejecting batches it would reject.
+// GetHiddenAuthors reports user 2 as blocked and user 3 as muted.
+type fakeUsers struct {
+	pb.UserServiceClient
+	batches []int
+}
+
+func (f *fakeUsers) GetHiddenAuthors(_ context.Context, req *pb.GetHiddenAuthorsRequest, _ ...grpc.CallOption) (*pb.GetHiddenAuthorsResponse, error) {
+	if req.GetBlocksOnly() {
+		return &pb.GetHiddenAuthorsResponse{Ids: []int32{2}}, nil
+	}
+	return &pb.GetHiddenAuthorsResponse{Ids: []int32{2, 3}}, nil
+}
+
+func (f *fakeUsers) BatchGetUsers(_ context.Context, req *pb.BatchGetUsersRequest, _ ...grpc.CallOption) (*pb.BatchGetUsersResponse, error) {
+	f.batches = append(f.batches, len(req.GetIds()))
+	if len(req.GetIds()) > maxAuthorsBatch {
+		return nil, status.Error(codes.InvalidArgument, "too many ids")
+	}
+
+	resp := &pb.BatchGetUsersResponse{}
+	for _, id := range req.GetIds() {
+		resp.Users = append(resp.Users, &pb.UserSummary{Id: id, Login: "user"})
+	}
+	return resp, nil
+}
+
+func TestGetAuthorsBatches(t *testing.T) {
+	tests := []struct {
+		name        string
+		ids         int
+		wantBatches []int
+	}{
+		{name: "one batch", ids: 3, wantBatches: []int{3}},
+		{name: "exactly the limit", ids: maxAuthorsBatch, wantBatches: []int{maxAuthorsBatch}},
+		{name: "over the limit", ids: 250, wantBatches: []int{100, 100, 50}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUsers{}
+			c := NewUserServiceClient(&config.Config{UserServiceTimeout: time.Second, AuthorCacheTTL: time.Minute}, users, nil)
+
+			ids := make([]int32, tt.ids)
+			for i := range ids {
+				ids[i] = int32(i + 1)
+			}
+			authors, err := c.GetAuthors(context.Background(), ids)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(users.batches) != len(tt.wantBatches) {
+				t.Fatalf("batches = %v, want %v", users.batches, tt.wantBatches)
+			}
+			for i := range tt.wantBatches {
+				if users.batches[i] != tt.wantBatches[i] {
+					t.Fatalf("batches = %v, want %v", users.batches, tt.wantBatches)
+				}
+			}
+			for _, id := range ids {
+				if authors[id] == nil {
+					t.Fatalf("author %d is missing", id)
+				}
+			}
+		})
+	}
+}
+
+func TestGetAuthorsCaches(t *testing.T) {
+	users := &fakeUsers{}
+	c := NewUserServiceClient(&config.Config{UserServiceTimeout: time.Second, AuthorCacheTTL: time.Minute}, users, nil)
+
+	_, err := c.GetAuthors(context.Background(), []int32{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	authors, err := c.GetAuthors(context.Background(), []int32{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(users.batches) != 2 || users.batches[1] != 1 {
+		t.Errorf("batches = %v, want only the uncached author looked up again", users.batches)
+	}
+	if authors[1] == nil || authors[3] == nil {
+		t.Errorf("authors = %v", authors)
+	}
+}
+
+func TestBlockedUsersLeavesMutesOut(t *testing.T) {
+	c := NewUserServiceClient(&config.Config{UserServiceTimeout: time.Second}, &fakeUsers{}, nil)
+
+	hidden, err := c.HiddenAuthors(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(hidden, []int32{2, 3}) {
+		t.Errorf("hidden authors = %v, want blocked and muted users", hidden)
+	}
+
+	blocked, err := c.BlockedUsers(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(blocked, []int32{2}) {
+		t.Errorf("blocked users = %v, want only the blocked user", blocked)
+	}
+}
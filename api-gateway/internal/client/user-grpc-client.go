@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	"social-network/api-gateway/internal/config"
+	"social-network/pkg/certs"
+	"social-network/pkg/internaltoken"
+	"social-network/pkg/logger"
+	"social-network/pkg/metrics"
+	"social-network/pkg/requestid"
+	pb "social-network/protos"
+)
+
+func NewUserGrpcClient(lc fx.Lifecycle, cfg *config.Config, internal *certs.Reloader) (pb.UserServiceClient, error) {
+	signer, err := internaltoken.NewSigner(cfg.InternalTokenKey, "api-gateway", "user-service")
+	if err != nil {
+		logger.Error("error creating internal token signer", "error", err)
+		return nil, err
+	}
+
+	conn, err := grpc.NewClient(
+		cfg.UserGrpcAddr,
+		grpc.WithTransportCredentials(certs.ClientCredentials(internal)),
+		grpc.WithChainUnaryInterceptor(
+			metrics.UnaryClientInterceptor(),
+			requestid.UnaryClientInterceptor(),
+			signer.UnaryClientInterceptor(),
+		),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
+	if err != nil {
+		logger.Error("error connecting to user service", "error", err)
+		return nil, err
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(_ context.Context) error {
+			return conn.Close()
+		},
+	})
+	return pb.NewUserServiceClient(conn), nil
+}
+
+// ServiceContext calls user-service on behalf of the api-gateway itself.
+func ServiceContext(ctx context.Context) context.Context {
+	return internaltoken.NewContext(ctx, internaltoken.Principal{Role: "service"})
+}
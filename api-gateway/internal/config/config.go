@@ -1,13 +1,165 @@
 package config
 
+import (
+	"social-network/pkg/certs"
+	"social-network/pkg/env"
+	"time"
+)
+
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RouteRateLimit applies to requests with the given method (any when empty)
+// and path. A path ending with "/" matches every path under it.
+type RouteRateLimit struct {
+	Method string
+	Path   string
+	RateLimit
+}
+
+// Upstream is a service the gateway proxies requests to.
+type Upstream struct {
+	Name string
+	Addr string
+	// Timeout bounds a whole proxied request, retries included
+	Timeout    time.Duration
+	MaxRetries int
+}
+
+// ProxyRoute sends requests for the path to the upstream. A path ending with
+// "/" matches every path under it. A non-empty Rewrite replaces the matched path.
+type ProxyRoute struct {
+	Path     string
+	Upstream string
+	Rewrite  string
+}
+
+type ProxyConfig struct {
+	Upstreams []Upstream
+	Routes    []ProxyRoute
+	// TrustedHeaders are set by the gateway once the caller is authenticated
+	// and are dropped from client requests. They must be every header
+	// JWTTokenVerify sets, which its test checks
+	TrustedHeaders      []string
+	DropRequestHeaders  []string
+	DropResponseHeaders []string
+	DialTimeout         time.Duration
+	MaxIdleConnsPerHost int
+}
+
 type Config struct {
-	Port     string
-	GrpcAddr string
+	Port            string
+	PostsGrpcAddr   string
+	UserServiceAddr string
+	UserGrpcAddr    string
+
+	// PostsCallTimeout bounds every call to posts-service, retries included
+	PostsCallTimeout        time.Duration
+	PostsMaxAttempts        int
+	PostsBreakerThreshold   int
+	PostsBreakerOpenTimeout time.Duration
+
+	UserStatusCacheTTL time.Duration
+	AuthorCacheTTL     time.Duration
+	// UserServiceTimeout bounds every internal call to user-service
+	UserServiceTimeout time.Duration
+
+	// ReadinessTimeout bounds the checks of the dependencies behind /readyz
+	ReadinessTimeout time.Duration
+
+	LoginIPFreeAttempts int
+	LoginIPBaseDelay    time.Duration
+	LoginIPMaxDelay     time.Duration
+	// LoginIPFailureDecay is how long it takes an IP address to be forgiven one failed login
+	LoginIPFailureDecay time.Duration
+
+	RateLimitStore   string
+	RedisAddr        string
+	DefaultRateLimit RateLimit
+	RouteRateLimits  []RouteRateLimit
+
+	// PublicTLS serves the gateway over HTTPS. InternalTLS is the certificate
+	// the gateway presents to the services and the CA it verifies them with
+	PublicTLS   certs.Config
+	InternalTLS certs.Config
+
+	// InternalTokenKey signs the tokens that tell the services which user a call or a
+	// proxied request is made for
+	InternalTokenKey string
+
+	Proxy ProxyConfig
 }
 
 func NewConfig() *Config {
+	userServiceAddr := "user-service:8081"
 	return &Config{
-		Port:     ":8080",
-		GrpcAddr: ":50051",
+		Port:            ":8080",
+		PostsGrpcAddr:   "posts-service:50051",
+		UserServiceAddr: userServiceAddr,
+		UserGrpcAddr:    "user-service:50052",
+
+		PostsCallTimeout:        5 * time.Second,
+		PostsMaxAttempts:        3,
+		PostsBreakerThreshold:   5,
+		PostsBreakerOpenTimeout: 10 * time.Second,
+
+		UserStatusCacheTTL: 3 * time.Second,
+		AuthorCacheTTL:     time.Minute,
+		UserServiceTimeout: 2 * time.Second,
+
+		ReadinessTimeout: 2 * time.Second,
+
+		LoginIPFreeAttempts: 20,
+		LoginIPBaseDelay:    time.Second,
+		LoginIPMaxDelay:     15 * time.Minute,
+		LoginIPFailureDecay: time.Minute,
+
+		RateLimitStore:   env.String("RATE_LIMIT_STORE", "memory"),
+		RedisAddr:        env.String("REDIS_ADDR", "redis:6379"),
+		DefaultRateLimit: RateLimit{Rate: 10, Burst: 20},
+		RouteRateLimits: []RouteRateLimit{
+			{Method: "POST", Path: "/register", RateLimit: RateLimit{Rate: 0.1, Burst: 3}},
+			{Method: "POST", Path: "/login", RateLimit: RateLimit{Rate: 1, Burst: 10}},
+			{Method: "POST", Path: "/password-reset/request", RateLimit: RateLimit{Rate: 0.05, Burst: 3}},
+			{Method: "POST", Path: "/user-profile/avatar", RateLimit: RateLimit{Rate: 0.05, Burst: 3}},
+			{Method: "GET", Path: "/user-profile/export", RateLimit: RateLimit{Rate: 0.01, Burst: 2}},
+			{Method: "POST", Path: "/post", RateLimit: RateLimit{Rate: 0.5, Burst: 5}},
+			{Method: "GET", Path: "/post", RateLimit: RateLimit{Rate: 5, Burst: 10}},
+			{Method: "GET", Path: "/post/", RateLimit: RateLimit{Rate: 10, Burst: 30}},
+			{Method: "POST", Path: "/post/", RateLimit: RateLimit{Rate: 0.1, Burst: 5}},
+		},
+
+		PublicTLS:   certs.NewConfig("PUBLIC_TLS"),
+		InternalTLS: certs.NewConfig("INTERNAL_TLS"),
+
+		InternalTokenKey: env.Secret("INTERNAL_TOKEN_KEY"),
+
+		Proxy: ProxyConfig{
+			Upstreams: []Upstream{
+				{Name: "user-service", Addr: userServiceAddr, Timeout: 15 * time.Second, MaxRetries: 2},
+			},
+			Routes: []ProxyRoute{
+				{Path: "/user-profile", Upstream: "user-service"},
+				{Path: "/user-profile/", Upstream: "user-service"},
+				{Path: "/users/", Upstream: "user-service"},
+				{Path: "/avatars/", Upstream: "user-service"},
+				{Path: "/verify-email", Upstream: "user-service"},
+				{Path: "/verify-email/", Upstream: "user-service"},
+				{Path: "/password-reset/", Upstream: "user-service"},
+				{Path: "/admin/", Upstream: "user-service"},
+			},
+			TrustedHeaders:      []string{"login", "name", "user_id", "role"},
+			DropRequestHeaders:  []string{"Authorization", "Cookie"},
+			DropResponseHeaders: []string{"Server", "X-Powered-By"},
+			DialTimeout:         2 * time.Second,
+			MaxIdleConnsPerHost: 32,
+		},
 	}
 }
+
+// NewInternalTLS supplies the config of the certificates the gateway reaches the services with.
+func NewInternalTLS(cfg *Config) certs.Config {
+	return cfg.InternalTLS
+}
@@ -1,5 +1,7 @@
 package errors
 
+import "social-network/pkg/validation"
+
 type JWTTokenEmpty struct{}
 
 func (jte *JWTTokenEmpty) Error() string {
@@ -11,3 +13,43 @@ type JWTTokenInvalid struct{}
 func (jti *JWTTokenInvalid) Error() string {
 	return "Token is invalid"
 }
+
+type PermissionDenied struct{}
+
+func (pd *PermissionDenied) Error() string {
+	return "Not enough permissions"
+}
+
+type FieldError = validation.FieldError
+
+type ValidationError = validation.ValidationError
+
+type TokenRevoked struct{}
+
+func (tr *TokenRevoked) Error() string {
+	return "Token is revoked"
+}
+
+type AccountSuspended struct {
+	Reason string
+}
+
+func (as *AccountSuspended) Error() string {
+	if as.Reason == "" {
+		return "Account is suspended"
+	}
+	return "Account is suspended: " + as.Reason
+}
+
+// StatusUnavailable means the token could not be checked against user-service.
+type StatusUnavailable struct{}
+
+func (su *StatusUnavailable) Error() string {
+	return "Unable to verify the token, try again later"
+}
+
+type UserNotFound struct{}
+
+func (unf *UserNotFound) Error() string {
+	return "User not found"
+}
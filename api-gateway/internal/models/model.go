@@ -1,13 +1,20 @@
 package models
 
 import (
+	pb "social-network/protos"
 	"time"
 )
 
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
 type RegisterModel struct {
-	Login    string `json:"login"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Login    string `json:"login" validate:"required,min=3,max=32,login"`
+	Email    string `json:"email" validate:"required,max=254,email"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
 }
 
 type LoginModel struct {
@@ -16,13 +23,203 @@ type LoginModel struct {
 }
 
 type UserModel struct {
-	Id           int       `json:"id" default:"0""`
-	Name         string    `json:"name" example:"" default:""`
-	FamilyName   string    `json:"family_name" example:"" default:""`
-	Login        string    `json:"login" example:"" default:""`
-	Email        string    `json:"email" example:"" default:""`
-	Password     string    `json:"password" example:"" default:""`
-	Phone        string    `json:"phone" example:"" default:""`
-	RegisteredAt time.Time `json:"registered_at" example:"2023-10-01T00:00:00Z"`
-	UpdatedAt    time.Time `json:"updated_at" example:"2023-10-01T00:00:00Z"`
+	Id            int          `json:"id" default:"0"`
+	Name          string       `json:"name" example:"" default:""`
+	FamilyName    string       `json:"family_name" example:"" default:""`
+	Login         string       `json:"login" example:"" default:""`
+	Email         string       `json:"email" example:"" default:""`
+	Password      string       `json:"password" example:"" default:""`
+	Phone         string       `json:"phone" example:"" default:""`
+	Avatar        string       `json:"avatar" example:"/avatars/1-abc.png" default:""`
+	Bio           string       `json:"bio" example:"" default:""`
+	Birthday      string       `json:"birthday" example:"1990-01-31" default:""`
+	Interests     []string     `json:"interests" example:"music,travel"`
+	Address       string       `json:"address" example:"" default:""`
+	PrivateInfo   string       `json:"private_info" example:"" default:""`
+	EmailVerified bool         `json:"email_verified" default:"false"`
+	Role          string       `json:"role" enums:"user,moderator,admin" default:"user"`
+	RegisteredAt  time.Time    `json:"registered_at" example:"2023-10-01T00:00:00Z"`
+	UpdatedAt     time.Time    `json:"updated_at" example:"2023-10-01T00:00:00Z"`
+	Privacy       PrivacyModel `json:"privacy"`
+}
+
+// PrivacyModel tells which profile fields other users see, id and login are always public,
+// address and private info never are.
+type PrivacyModel struct {
+	Name         bool `json:"name" default:"true"`
+	FamilyName   bool `json:"family_name" default:"true"`
+	Avatar       bool `json:"avatar" default:"true"`
+	Bio          bool `json:"bio" default:"true"`
+	Birthday     bool `json:"birthday" default:"false"`
+	Interests    bool `json:"interests" default:"true"`
+	RegisteredAt bool `json:"registered_at" default:"true"`
+	PostsCount   bool `json:"posts_count" default:"true"`
+}
+
+type PublicProfileModel struct {
+	Id           int        `json:"id"`
+	Login        string     `json:"login"`
+	Name         string     `json:"name,omitempty"`
+	FamilyName   string     `json:"family_name,omitempty"`
+	Avatar       string     `json:"avatar,omitempty"`
+	Bio          string     `json:"bio,omitempty"`
+	Birthday     string     `json:"birthday,omitempty"`
+	Interests    []string   `json:"interests,omitempty"`
+	RegisteredAt *time.Time `json:"registered_at,omitempty"`
+	PostsCount   *int32     `json:"posts_count,omitempty"`
+}
+
+type UserProfilePatchModel struct {
+	Name        *string   `json:"name,omitempty" example:""`
+	FamilyName  *string   `json:"family_name,omitempty" example:""`
+	Phone       *string   `json:"phone,omitempty" example:""`
+	Bio         *string   `json:"bio,omitempty" example:""`
+	Birthday    *string   `json:"birthday,omitempty" example:"1990-01-31"`
+	Interests   *[]string `json:"interests,omitempty" example:"music,travel"`
+	Address     *string   `json:"address,omitempty" example:""`
+	PrivateInfo *string   `json:"private_info,omitempty" example:""`
+}
+
+type AvatarModel struct {
+	Avatar string `json:"avatar" example:"/avatars/1-abc.png"`
+}
+
+type PasswordChangeModel struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type EmailChangeModel struct {
+	Password string `json:"password"`
+	Email    string `json:"email"`
+}
+
+type EmailVerificationModel struct {
+	Token string `json:"token"`
+}
+
+type PasswordResetRequestModel struct {
+	Email string `json:"email"`
+}
+
+type PasswordResetConfirmModel struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+type AccountDeletionModel struct {
+	Password string `json:"password"`
+}
+
+type AccountDeletionResponseModel struct {
+	DeleteAfter time.Time `json:"delete_after"`
+}
+
+type RelationModel struct {
+	Login string `json:"login"`
+}
+
+type RelatedUserModel struct {
+	Id        int       `json:"id"`
+	Login     string    `json:"login"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AdminUserModel struct {
+	Login string `json:"login"`
+}
+
+type SuspendModel struct {
+	Login  string `json:"login"`
+	Reason string `json:"reason"`
+}
+
+type UserSummaryModel struct {
+	Id            int        `json:"id"`
+	Login         string     `json:"login"`
+	Email         string     `json:"email"`
+	Name          string     `json:"name"`
+	FamilyName    string     `json:"family_name"`
+	Role          string     `json:"role"`
+	EmailVerified bool       `json:"email_verified"`
+	RegisteredAt  time.Time  `json:"registered_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+	SuspendedAt   *time.Time `json:"suspended_at,omitempty"`
+	SuspendReason string     `json:"suspend_reason,omitempty"`
+}
+
+type UserPageModel struct {
+	Users []UserSummaryModel `json:"users"`
+	Total int                `json:"total"`
+}
+
+// RequeuedModel tells how many parked outbox events were put back in the queue.
+type RequeuedModel struct {
+	Events int `json:"events"`
+}
+
+// UserStatus is what the gateway checks on every authenticated request.
+type UserStatus struct {
+	Id            int    `json:"id"`
+	Role          string `json:"role"`
+	Suspended     bool   `json:"suspended"`
+	SuspendReason string `json:"suspend_reason,omitempty"`
+	TokenVersion  int    `json:"token_version"`
+}
+
+type RoleChangeModel struct {
+	Login string `json:"login"`
+	Role  string `json:"role" enums:"user,moderator,admin"`
+}
+
+// AuthorModel is the part of the author's public profile shown next to a post.
+type AuthorModel struct {
+	Id          int32  `json:"id"`
+	Login       string `json:"login"`
+	DisplayName string `json:"display_name,omitempty"`
+	Avatar      string `json:"avatar,omitempty"`
+}
+
+// PostModel is a post as posts-comments-service returns it with the author
+// embedded, author is null when the author's profile is unavailable.
+type PostModel struct {
+	*pb.Post
+	Author *AuthorModel `json:"author"`
+}
+
+type PostListModel struct {
+	Posts []PostModel `json:"posts"`
+}
+
+type ReportPostModel struct {
+	Reason  string `json:"reason" enums:"spam,harassment,hate_speech,violence,nudity,misinformation,other"`
+	Comment string `json:"comment"`
+}
+
+type ReportStatusModel struct {
+	Status string `json:"status" enums:"open,reviewing,actioned,dismissed"`
+}
+
+type ReportModel struct {
+	Id         int32     `json:"id"`
+	PostId     int32     `json:"post_id"`
+	ReporterId int32     `json:"reporter_id"`
+	Reason     string    `json:"reason"`
+	Comment    string    `json:"comment"`
+	Status     string    `json:"status"`
+	ReviewerId int32     `json:"reviewer_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type ReportListModel struct {
+	Reports []ReportModel `json:"reports"`
+	Total   int32         `json:"total"`
+}
+
+// UpstreamHealth describes the connection of the gateway to a service.
+type UpstreamHealth struct {
+	Name       string `json:"name"`
+	Connection string `json:"connection" enums:"IDLE,CONNECTING,READY,TRANSIENT_FAILURE,SHUTDOWN"`
+	Breaker    string `json:"breaker" enums:"closed,open,half-open"`
 }
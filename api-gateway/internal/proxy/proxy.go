@@ -0,0 +1,256 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"social-network/api-gateway/internal/config"
+	"social-network/pkg/certs"
+	"social-network/pkg/internaltoken"
+	"social-network/pkg/logger"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRetryBody is the largest request body buffered so that an idempotent request can be retried.
+const maxRetryBody = 64 << 10
+
+// Proxy forwards requests to the upstream services by the route table. It is
+// built once and shares a pooled transport between all requests.
+type Proxy struct {
+	routes              []route
+	reverseProxy        *httputil.ReverseProxy
+	trustedHeaders      []string
+	dropRequestHeaders  []string
+	dropResponseHeaders []string
+}
+
+type route struct {
+	path     string
+	rewrite  string
+	upstream *upstream
+}
+
+type upstream struct {
+	name       string
+	target     *url.URL
+	timeout    time.Duration
+	maxRetries int
+	// signer issues the internal tokens the upstream authenticates requests by,
+	// with the upstream's name as their audience
+	signer *internaltoken.Signer
+}
+
+type routeKey struct{}
+
+func New(cfg *config.Config, internal *certs.Reloader) (*Proxy, error) {
+	upstreams := make(map[string]*upstream, len(cfg.Proxy.Upstreams))
+	for _, u := range cfg.Proxy.Upstreams {
+		signer, err := internaltoken.NewSigner(cfg.InternalTokenKey, "api-gateway", u.Name)
+		if err != nil {
+			return nil, fmt.Errorf("proxy upstream %s: %w", u.Name, err)
+		}
+		upstreams[u.Name] = &upstream{
+			name:       u.Name,
+			target:     &url.URL{Scheme: certs.Scheme(internal), Host: u.Addr},
+			timeout:    u.Timeout,
+			maxRetries: u.MaxRetries,
+			signer:     signer,
+		}
+	}
+
+	p := &Proxy{
+		trustedHeaders:      cfg.Proxy.TrustedHeaders,
+		dropRequestHeaders:  cfg.Proxy.DropRequestHeaders,
+		dropResponseHeaders: cfg.Proxy.DropResponseHeaders,
+	}
+	for _, r := range cfg.Proxy.Routes {
+		u, ok := upstreams[r.Upstream]
+		if !ok {
+			return nil, fmt.Errorf("proxy route %s: unknown upstream %s", r.Path, r.Upstream)
+		}
+		p.routes = append(p.routes, route{path: r.Path, rewrite: r.Rewrite, upstream: u})
+	}
+
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   cfg.Proxy.DialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: cfg.Proxy.MaxIdleConnsPerHost,
+		IdleConnTimeout:     90 * time.Second,
+		TLSClientConfig:     certs.ClientTLS(internal),
+	}
+	// every attempt gets its own client span carrying the trace context upstream
+	traced := otelhttp.NewTransport(transport, otelhttp.WithSpanNameFormatter(spanName))
+	p.reverseProxy = &httputil.ReverseProxy{
+		Rewrite:        p.rewrite,
+		Transport:      &retryTransport{base: traced},
+		ModifyResponse: p.modifyResponse,
+		ErrorHandler:   p.handleError,
+	}
+	return p, nil
+}
+
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rt, ok := p.match(r.URL.Path)
+	if !ok {
+		logger.ErrorContext(r.Context(), "no proxy route", "method", r.Method, "path", r.URL.Path)
+		writeError(w, http.StatusNotFound, "no route to upstream")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), rt.upstream.timeout)
+	defer cancel()
+	r = r.WithContext(context.WithValue(ctx, routeKey{}, rt))
+
+	if rt.upstream.maxRetries > 0 && isIdempotent(r.Method) {
+		err := bufferBody(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "failed to read request body")
+			return
+		}
+	}
+	p.reverseProxy.ServeHTTP(w, r)
+}
+
+func spanName(_ string, r *http.Request) string {
+	if rt, ok := r.Context().Value(routeKey{}).(*route); ok {
+		return "proxy " + rt.upstream.name + " " + r.Method
+	}
+	return "proxy " + r.Method
+}
+
+// StripTrustedHeaders drops the headers the gateway sets once the caller is
+// authenticated, so that clients can't pass them to the services themselves.
+func (p *Proxy) StripTrustedHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, header := range p.trustedHeaders {
+			r.Header.Del(header)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Principal returns the user JWTTokenVerify authenticated the request as.
+// The headers it reads are dropped from client requests by StripTrustedHeaders.
+func Principal(r *http.Request) (internaltoken.Principal, bool) {
+	userId, err := strconv.Atoi(r.Header.Get("user_id"))
+	if err != nil {
+		return internaltoken.Principal{}, false
+	}
+	return internaltoken.Principal{
+		UserId: int32(userId),
+		Login:  r.Header.Get("login"),
+		Role:   r.Header.Get("role"),
+	}, true
+}
+
+// match picks the longest route matching the path.
+func (p *Proxy) match(path string) (*route, bool) {
+	var best *route
+	for i := range p.routes {
+		rt := &p.routes[i]
+		matches := rt.path == path || strings.HasSuffix(rt.path, "/") && strings.HasPrefix(path, rt.path)
+		if matches && (best == nil || len(rt.path) > len(best.path)) {
+			best = rt
+		}
+	}
+	return best, best != nil
+}
+
+func (p *Proxy) rewrite(pr *httputil.ProxyRequest) {
+	rt := pr.In.Context().Value(routeKey{}).(*route)
+
+	pr.SetURL(rt.upstream.target)
+	if rt.rewrite != "" {
+		pr.Out.URL.Path = rt.rewrite + strings.TrimPrefix(pr.In.URL.Path, rt.path)
+		pr.Out.URL.RawPath = ""
+	}
+	pr.SetXForwarded()
+
+	for _, header := range p.dropRequestHeaders {
+		pr.Out.Header.Del(header)
+	}
+	p.authenticate(pr, rt.upstream)
+}
+
+// authenticate passes the caller to the upstream as the principal of an
+// internal token instead of the trusted headers, which the upstream would
+// have to take on faith. Requests without a user go out as anonymous.
+func (p *Proxy) authenticate(pr *httputil.ProxyRequest, u *upstream) {
+	for _, header := range p.trustedHeaders {
+		pr.Out.Header.Del(header)
+	}
+	pr.Out.Header.Del(internaltoken.Header)
+
+	principal, ok := Principal(pr.In)
+	if !ok {
+		principal = internaltoken.Principal{Role: internaltoken.RoleAnonymous}
+	}
+	token, err := u.signer.Sign(principal)
+	if err != nil {
+		// the upstream rejects the request without a token
+		logger.ErrorContext(pr.In.Context(), "failed to sign internal token", "upstream", u.name, "error", err)
+		return
+	}
+	pr.Out.Header.Set(internaltoken.Header, token)
+}
+
+func (p *Proxy) modifyResponse(resp *http.Response) error {
+	for _, header := range p.dropResponseHeaders {
+		resp.Header.Del(header)
+	}
+	return nil
+}
+
+func (p *Proxy) handleError(w http.ResponseWriter, r *http.Request, err error) {
+	rt := r.Context().Value(routeKey{}).(*route)
+	logger.ErrorContext(r.Context(), "proxy request failed", "method", r.Method, "path", r.URL.Path, "upstream", rt.upstream.name, "error", err)
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		writeError(w, http.StatusGatewayTimeout, rt.upstream.name+" did not respond in time")
+		return
+	}
+	writeError(w, http.StatusBadGateway, rt.upstream.name+" is unavailable")
+}
+
+// bufferBody reads a small body into memory so the transport can send it again.
+func bufferBody(r *http.Request) error {
+	if r.Body == nil || r.Body == http.NoBody || r.ContentLength < 0 || r.ContentLength > maxRetryBody {
+		return nil
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	_ = r.Body.Close()
+
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	return nil
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(struct {
+		Message string `json:"message"`
+	}{
+		Message: message,
+	})
+}
@@ -0,0 +1,279 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"social-network/api-gateway/internal/config"
+	"social-network/pkg/internaltoken"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testKey = "test-key"
+
+// newTestProxy routes every path under "/" to upstream, with cfg changed by configure.
+func newTestProxy(t *testing.T, upstream *httptest.Server, configure func(*config.ProxyConfig)) *Proxy {
+	t.Helper()
+	target, err := url.Parse(upstream.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		InternalTokenKey: testKey,
+		Proxy: config.ProxyConfig{
+			Upstreams:      []config.Upstream{{Name: "user-service", Addr: target.Host, Timeout: 5 * time.Second}},
+			Routes:         []config.ProxyRoute{{Path: "/", Upstream: "user-service"}},
+			TrustedHeaders: []string{"login", "user_id", "role"},
+			DialTimeout:    time.Second,
+		},
+	}
+	if configure != nil {
+		configure(&cfg.Proxy)
+	}
+
+	p, err := New(cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestAuthenticate(t *testing.T) {
+	verifier, err := internaltoken.NewVerifier(testKey, "user-service")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    internaltoken.Principal
+	}{
+		{
+			name:    "authenticated user",
+			headers: map[string]string{"user_id": "7", "login": "alice", "role": "user"},
+			want:    internaltoken.Principal{UserId: 7, Login: "alice", Role: "user"},
+		},
+		{
+			name: "anonymous",
+			want: internaltoken.Principal{Role: internaltoken.RoleAnonymous},
+		},
+		{
+			name:    "token sent by the client is replaced",
+			headers: map[string]string{internaltoken.Header: "forged"},
+			want:    internaltoken.Principal{Role: internaltoken.RoleAnonymous},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received http.Header
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received = r.Header.Clone()
+			}))
+			defer upstream.Close()
+			p := newTestProxy(t, upstream, nil)
+
+			r := httptest.NewRequest(http.MethodGet, "/user-profile/blocks", nil)
+			for name, value := range tt.headers {
+				r.Header.Set(name, value)
+			}
+			p.ServeHTTP(httptest.NewRecorder(), r)
+
+			for _, header := range []string{"login", "user_id", "role"} {
+				if received.Get(header) != "" {
+					t.Errorf("upstream got the %s header", header)
+				}
+			}
+			got, err := verifier.VerifyRequest(&http.Request{Header: received})
+			if err != nil {
+				t.Fatalf("upstream got no valid token: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("principal = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	p := &Proxy{routes: []route{
+		{path: "/"},
+		{path: "/post"},
+		{path: "/post/"},
+		{path: "/admin/users/"},
+		{path: "/admin/users/stats"},
+	}}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/post", want: "/post"},
+		{path: "/post/12", want: "/post/"},
+		{path: "/posts", want: "/"},
+		{path: "/admin/users/stats", want: "/admin/users/stats"},
+		{path: "/admin/users/stats/more", want: "/admin/users/"},
+		{path: "/admin/users", want: "/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rt, ok := p.match(tt.path)
+			if !ok {
+				t.Fatal("no route matched")
+			}
+			if rt.path != tt.want {
+				t.Errorf("route = %s, want %s", rt.path, tt.want)
+			}
+		})
+	}
+
+	if _, ok := (&Proxy{routes: []route{{path: "/post/"}}}).match("/user"); ok {
+		t.Error("matched a path outside every route")
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	var gotPath string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+	}))
+	defer upstream.Close()
+	p := newTestProxy(t, upstream, func(cfg *config.ProxyConfig) {
+		cfg.Routes = append(cfg.Routes, config.ProxyRoute{Path: "/admin/users/", Upstream: "user-service", Rewrite: "/users/"})
+	})
+
+	p.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/admin/users/7/suspend", nil))
+	if gotPath != "/users/7/suspend" {
+		t.Errorf("upstream path = %s, want /users/7/suspend", gotPath)
+	}
+
+	w := httptest.NewRecorder()
+	newTestProxy(t, upstream, func(cfg *config.ProxyConfig) {
+		cfg.Routes = []config.ProxyRoute{{Path: "/post/", Upstream: "user-service"}}
+	}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/user", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status without a route = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestHeaderStripping(t *testing.T) {
+	var received http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.Header().Set("Server", "upstream")
+		w.Header().Set("X-Powered-By", "go")
+		w.Header().Set("Content-Type", "application/json")
+	}))
+	defer upstream.Close()
+	p := newTestProxy(t, upstream, func(cfg *config.ProxyConfig) {
+		cfg.DropRequestHeaders = []string{"Cookie"}
+		cfg.DropResponseHeaders = []string{"Server", "X-Powered-By"}
+	})
+
+	// the trusted headers a client sends are dropped before the gateway authenticates it
+	var afterStrip http.Header
+	handler := p.StripTrustedHeaders(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		afterStrip = r.Header.Clone()
+		p.ServeHTTP(w, r)
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/user-profile", nil)
+	r.Header.Set("user_id", "1")
+	r.Header.Set("role", "admin")
+	r.Header.Set("login", "root")
+	r.Header.Set("Cookie", "session=1")
+	r.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+
+	for _, header := range []string{"user_id", "role", "login"} {
+		if afterStrip.Get(header) != "" {
+			t.Errorf("%s reached the handler", header)
+		}
+	}
+	if received.Get("Cookie") != "" {
+		t.Error("dropped request header reached the upstream")
+	}
+	if received.Get("Accept") != "application/json" {
+		t.Error("other request headers are not passed on")
+	}
+	if received.Get("X-Forwarded-For") == "" {
+		t.Error("X-Forwarded-For is not set")
+	}
+	if w.Header().Get("Server") != "" || w.Header().Get("X-Powered-By") != "" {
+		t.Errorf("dropped response headers reached the client: %v", w.Header())
+	}
+	if w.Header().Get("Content-Type") != "application/json" {
+		t.Error("other response headers are not passed on")
+	}
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		body         string
+		failures     int
+		wantAttempts int
+		wantStatus   int
+	}{
+		{name: "GET is retried", method: http.MethodGet, failures: 2, wantAttempts: 3, wantStatus: http.StatusOK},
+		{name: "PUT is retried with its body", method: http.MethodPut, body: `{"bio":"hi"}`, failures: 1, wantAttempts: 2, wantStatus: http.StatusOK},
+		{name: "DELETE is retried", method: http.MethodDelete, failures: 1, wantAttempts: 2, wantStatus: http.StatusOK},
+		{name: "POST is not retried", method: http.MethodPost, body: `{"name":"post"}`, failures: 1, wantAttempts: 1, wantStatus: http.StatusServiceUnavailable},
+		{name: "PATCH is not retried", method: http.MethodPatch, body: `{}`, failures: 1, wantAttempts: 1, wantStatus: http.StatusServiceUnavailable},
+		{name: "retries run out", method: http.MethodGet, failures: 5, wantAttempts: 3, wantStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			var bodies []string
+			upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				data, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(data))
+				if attempts <= tt.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			}))
+			defer upstream.Close()
+			p := newTestProxy(t, upstream, func(cfg *config.ProxyConfig) {
+				cfg.Upstreams[0].MaxRetries = 2
+			})
+
+			w := httptest.NewRecorder()
+			p.ServeHTTP(w, httptest.NewRequest(tt.method, "/user-profile", strings.NewReader(tt.body)))
+
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			for i, body := range bodies {
+				if body != tt.body {
+					t.Errorf("attempt %d sent body %q, want %q", i+1, body, tt.body)
+				}
+			}
+		})
+	}
+}
+
+func TestUnreachableUpstream(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	p := newTestProxy(t, upstream, nil)
+	upstream.Close()
+
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/user-profile", nil))
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadGateway)
+	}
+}
@@ -0,0 +1,72 @@
+package proxy
+
+import (
+	"net/http"
+	"time"
+)
+
+const retryBaseDelay = 50 * time.Millisecond
+
+// retryTransport resends idempotent requests when the upstream can't be
+// reached or answers 503, up to the retries of the request's upstream.
+type retryTransport struct {
+	base http.RoundTripper
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attempts := 1
+	if rt, ok := req.Context().Value(routeKey{}).(*route); ok && isIdempotent(req.Method) && canResend(req) {
+		attempts += rt.upstream.maxRetries
+	}
+
+	delay := retryBaseDelay
+	for attempt := 1; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		retry := err != nil || resp.StatusCode == http.StatusServiceUnavailable
+		if !retry || attempt == attempts {
+			return resp, err
+		}
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+
+		req, err = rewind(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func canResend(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewind returns a copy of the request with a fresh body for the next attempt.
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.GetBody == nil {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	next := req.Clone(req.Context())
+	next.Body = body
+	return next, nil
+}
@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"social-network/api-gateway/internal/config"
+	"social-network/pkg/logger"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type Limiter struct {
+	store        Store
+	routes       []config.RouteRateLimit
+	defaultLimit Limit
+}
+
+func NewStore(cfg *config.Config) Store {
+	if cfg.RateLimitStore == "redis" {
+		return NewRedisStore(redis.NewClient(&redis.Options{Addr: cfg.RedisAddr}))
+	}
+	return NewMemoryStore()
+}
+
+func NewLimiter(cfg *config.Config, store Store) *Limiter {
+	return &Limiter{
+		store:        store,
+		routes:       cfg.RouteRateLimits,
+		defaultLimit: Limit(cfg.DefaultRateLimit),
+	}
+}
+
+// Middleware limits requests per route and per caller, the caller is identified by keyFunc.
+// When the store is unavailable requests are let through.
+func (l *Limiter) Middleware(next http.Handler, keyFunc func(r *http.Request) string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, limit := l.match(r)
+
+		result, err := l.store.Take(r.Context(), route+"|"+keyFunc(r), limit)
+		if err != nil {
+			logger.ErrorContext(r.Context(), "rate limit store failed", "error", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header := w.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		header.Set("RateLimit-Reset", strconv.Itoa(seconds(result.Reset)))
+		header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Burst, seconds(limit.window())))
+
+		if !result.Allowed {
+			logger.WarnContext(r.Context(), "rate limit exceeded", "method", r.Method, "path", r.URL.Path)
+			header.Set("Retry-After", strconv.Itoa(seconds(result.RetryAfter)))
+			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// match picks the most specific route limit for the request.
+func (l *Limiter) match(r *http.Request) (string, Limit) {
+	var best *config.RouteRateLimit
+	for i := range l.routes {
+		route := &l.routes[i]
+		if route.Method != "" && route.Method != r.Method {
+			continue
+		}
+		if route.Path != r.URL.Path && !(strings.HasSuffix(route.Path, "/") && strings.HasPrefix(r.URL.Path, route.Path)) {
+			continue
+		}
+		if best == nil || len(route.Path) > len(best.Path) || (len(route.Path) == len(best.Path) && route.Method != "") {
+			best = route
+		}
+	}
+
+	if best == nil {
+		return "default", l.defaultLimit
+	}
+	return best.Method + " " + best.Path, Limit(best.RateLimit)
+}
+
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
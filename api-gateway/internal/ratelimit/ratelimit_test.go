@@ -0,0 +1,180 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"social-network/api-gateway/internal/config"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// checkStore drains a bucket, checks that it refuses once empty and that it refills with time.
+func checkStore(t *testing.T, store Store) {
+	t.Helper()
+	ctx := context.Background()
+	key := "test|" + t.Name() + "|" + time.Now().Format(time.RFC3339Nano)
+	limit := Limit{Rate: 20, Burst: 2}
+
+	for i, wantRemaining := range []int{1, 0} {
+		result, err := store.Take(ctx, key, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Allowed || result.Remaining != wantRemaining || result.Limit != 2 {
+			t.Fatalf("take %d = %+v, want allowed with %d remaining", i+1, result, wantRemaining)
+		}
+	}
+
+	result, err := store.Take(ctx, key, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Allowed || result.RetryAfter <= 0 || result.RetryAfter > 50*time.Millisecond {
+		t.Fatalf("take from an empty bucket = %+v, want refused with a retry within 50ms", result)
+	}
+
+	// one token comes back every 50ms
+	time.Sleep(60 * time.Millisecond)
+	result, err = store.Take(ctx, key, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Allowed {
+		t.Fatalf("take after a refill = %+v, want allowed", result)
+	}
+
+	result, err = store.Take(ctx, "other|"+key, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Allowed || result.Remaining != 1 {
+		t.Fatalf("take from another key = %+v, want a full bucket", result)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	checkStore(t, NewMemoryStore())
+}
+
+func TestMemoryStoreRefillIsCappedAtBurst(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{Rate: 1, Burst: 3}
+
+	_, _ = store.Take(context.Background(), "key", limit)
+	store.buckets["key"].updatedAt = time.Now().Add(-time.Hour)
+
+	result, _ := store.Take(context.Background(), "key", limit)
+	if result.Remaining != 2 {
+		t.Errorf("remaining = %d, want the burst minus the token just taken", result.Remaining)
+	}
+}
+
+func TestRedisStore(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+
+	checkStore(t, NewRedisStore(client))
+
+	// the bucket expires once it would have refilled completely
+	_, err := NewRedisStore(client).Take(context.Background(), "expiring", Limit{Rate: 0.5, Burst: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ttl := server.TTL("ratelimit:expiring"); ttl != 10*time.Second {
+		t.Errorf("ttl = %v, want the time to refill the burst", ttl)
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, Limit) (Result, error) {
+	return Result{}, errors.New("store is down")
+}
+
+func TestMiddlewareHeaders(t *testing.T) {
+	cfg := &config.Config{
+		DefaultRateLimit: config.RateLimit{Rate: 10, Burst: 20},
+		RouteRateLimits: []config.RouteRateLimit{
+			{Method: http.MethodPost, Path: "/login", RateLimit: config.RateLimit{Rate: 1, Burst: 2}},
+		},
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	handler := NewLimiter(cfg, NewMemoryStore()).Middleware(next, func(r *http.Request) string { return "client" })
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		header map[string]string
+	}{
+		{
+			name:   "first request",
+			path:   "/login",
+			status: http.StatusOK,
+			header: map[string]string{
+				"RateLimit-Limit":     "2",
+				"RateLimit-Remaining": "1",
+				"RateLimit-Reset":     "1",
+				"RateLimit-Policy":    "2;w=2",
+				"Retry-After":         "",
+			},
+		},
+		{
+			name:   "last token",
+			path:   "/login",
+			status: http.StatusOK,
+			header: map[string]string{"RateLimit-Remaining": "0", "RateLimit-Reset": "2"},
+		},
+		{
+			name:   "limited",
+			path:   "/login",
+			status: http.StatusTooManyRequests,
+			header: map[string]string{"RateLimit-Remaining": "0", "Retry-After": "1"},
+		},
+		{
+			name:   "other route has its own bucket",
+			path:   "/post",
+			status: http.StatusOK,
+			header: map[string]string{"RateLimit-Limit": "20", "RateLimit-Remaining": "19", "RateLimit-Policy": "20;w=2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.path, nil))
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			for name, want := range tt.header {
+				if got := w.Header().Get(name); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMiddlewareLetsRequestsThroughWhenStoreFails(t *testing.T) {
+	called := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })
+	handler := NewLimiter(&config.Config{DefaultRateLimit: config.RateLimit{Rate: 1, Burst: 1}}, failingStore{}).
+		Middleware(next, func(r *http.Request) string { return "client" })
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/post", nil))
+
+	if !called || w.Code != http.StatusOK {
+		t.Errorf("called = %v, status = %d, want the request let through", called, w.Code)
+	}
+	if w.Header().Get("RateLimit-Limit") != "" {
+		t.Error("rate limit headers are set without a result")
+	}
+}
@@ -1,16 +1,32 @@
 package server
 
 import (
-	"context"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.uber.org/fx"
 	"net/http"
 	"social-network/api-gateway/internal/app"
+	"social-network/api-gateway/internal/client"
 	"social-network/api-gateway/internal/config"
-	"social-network/api-gateway/internal/logger"
+	"social-network/api-gateway/internal/models"
+	"social-network/api-gateway/internal/proxy"
+	"social-network/api-gateway/internal/ratelimit"
+	"social-network/pkg/certs"
+	"social-network/pkg/health"
+	"social-network/pkg/metrics"
+	"social-network/pkg/requestid"
+	"social-network/pkg/tracing"
+	"strings"
 )
 
-func NewServer(cfg *config.Config, app *app.App) *http.Server {
+func NewServer(
+	lc fx.Lifecycle,
+	cfg *config.Config,
+	app *app.App,
+	limiter *ratelimit.Limiter,
+	proxy *proxy.Proxy,
+	checker *health.Checker,
+) (*http.Server, error) {
 	mux := http.NewServeMux()
 	mux.Handle("/register", http.HandlerFunc(app.Register))
 	mux.Handle("/login", http.HandlerFunc(app.Login))
@@ -20,10 +36,43 @@ func NewServer(cfg *config.Config, app *app.App) *http.Server {
 			app.GetUserProfile(w, r)
 		case http.MethodPut:
 			app.UpdateUserProfile(w, r)
+		case http.MethodPatch:
+			app.PatchUserProfile(w, r)
+		case http.MethodDelete:
+			app.DeleteAccount(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.Handle("/user-profile/password", http.HandlerFunc(app.ChangePassword))
+	mux.Handle("/user-profile/email", http.HandlerFunc(app.ChangeEmail))
+	mux.Handle("/user-profile/privacy", http.HandlerFunc(app.UpdatePrivacy))
+	mux.HandleFunc("/user-profile/avatar", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			app.UploadAvatar(w, r)
+		case http.MethodDelete:
+			app.DeleteAvatar(w, r)
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
 	})
+	mux.Handle("/avatars/", http.HandlerFunc(app.GetAvatar))
+	mux.HandleFunc("/users/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/users/by-login/") {
+			app.GetPublicProfileByLogin(w, r)
+			return
+		}
+		app.GetPublicProfile(w, r)
+	})
+	mux.Handle("/user-profile/restore", http.HandlerFunc(app.RestoreAccount))
+	mux.Handle("/user-profile/export", http.HandlerFunc(app.ExportUserData))
+	mux.HandleFunc("/user-profile/blocks", relationHandler(app))
+	mux.HandleFunc("/user-profile/mutes", relationHandler(app))
+	mux.Handle("/verify-email", http.HandlerFunc(app.VerifyEmail))
+	mux.Handle("/verify-email/resend", http.HandlerFunc(app.ResendEmailVerification))
+	mux.Handle("/password-reset/request", http.HandlerFunc(app.RequestPasswordReset))
+	mux.Handle("/password-reset/confirm", http.HandlerFunc(app.ConfirmPasswordReset))
 	mux.HandleFunc("/post", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodPost:
@@ -38,31 +87,83 @@ func NewServer(cfg *config.Config, app *app.App) *http.Server {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
 	})
-	mux.Handle("/post/", http.HandlerFunc(app.GetPostById))
+	mux.HandleFunc("/post/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			app.GetPostById(w, r)
+		case http.MethodPatch:
+			app.PatchPost(w, r)
+		case http.MethodPost:
+			app.ReportPost(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.Handle("/admin/unlock", app.RequireRole(app.UnlockUser, models.RoleAdmin))
+	mux.Handle("/admin/role", app.RequireRole(app.ChangeUserRole, models.RoleAdmin))
+	mux.Handle("/admin/users", app.RequireRole(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			app.SearchUsers(w, r)
+		case http.MethodDelete:
+			app.DeleteUser(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}, models.RoleAdmin))
+	mux.Handle("/admin/users/suspend", app.RequireRole(app.SuspendUser, models.RoleAdmin))
+	mux.Handle("/admin/users/unsuspend", app.RequireRole(app.UnsuspendUser, models.RoleAdmin))
+	mux.Handle("/admin/users/logout", app.RequireRole(app.ForceLogout, models.RoleAdmin))
+	mux.Handle("/admin/outbox/requeue", app.RequireRole(app.RequeueOutbox, models.RoleAdmin))
+	mux.Handle("/moderation/post/", app.RequireRole(app.HidePost, models.RoleModerator, models.RoleAdmin))
+	mux.Handle("/moderation/reports", app.RequireRole(app.ListReports, models.RoleModerator, models.RoleAdmin))
+	mux.Handle("/moderation/reports/", app.RequireRole(app.UpdateReportStatus, models.RoleModerator, models.RoleAdmin))
+
+	mux.Handle("/health/upstreams", http.HandlerFunc(app.UpstreamsHealth))
+	mux.Handle("/healthz", http.HandlerFunc(health.Live))
+	mux.Handle("/readyz", http.HandlerFunc(checker.Ready))
+	mux.Handle("/metrics", metrics.Handler())
 
 	mux.Handle("/swagger/", httpSwagger.Handler(httpSwagger.URL("swagger/swagger/doc.json")))
 
-	return &http.Server{
-		Addr:    cfg.Port,
-		Handler: mux,
+	handler := limiter.Middleware(proxy.StripTrustedHeaders(mux), app.RequesterKey)
+	handler = metrics.Middleware(mux, handler)
+	handler = requestid.Issue(handler)
+	handler = tracing.Middleware(mux, handler, otelhttp.WithPublicEndpoint())
+
+	// clients are served the public certificate, the internal one stays between the services
+	publicCerts, err := certs.NewReloader(lc, cfg.PublicTLS)
+	if err != nil {
+		return nil, err
 	}
+
+	return &http.Server{
+		Addr:      cfg.Port,
+		Handler:   handler,
+		TLSConfig: certs.ServerTLS(publicCerts),
+	}, nil
 }
 
-func InvokeServer(lc fx.Lifecycle, srv *http.Server) error {
-	lc.Append(fx.Hook{
-		OnStart: func(_ context.Context) error {
-			go func() {
-				logger.Info("starting server on " + srv.Addr)
-				if err := srv.ListenAndServe(); err != nil {
-					logger.Error("error starting server: " + err.Error())
-				}
-			}()
-			return nil
-		},
-		OnStop: func(ctx context.Context) error {
-			return srv.Shutdown(ctx)
-		},
+// NewHealthChecker makes the gateway ready once both services it routes to can be reached.
+func NewHealthChecker(cfg *config.Config, postsConn *client.PostsConnection, userClient *client.UserServiceClient) *health.Checker {
+	return health.NewChecker(cfg.ReadinessTimeout, map[string]health.Check{
+		"posts-service": postsConn.Check,
+		"user-service":  userClient.Ping,
 	})
+}
 
-	return nil
+func relationHandler(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			app.ListRelations(w, r)
+		case http.MethodPost:
+			app.AddRelation(w, r)
+		case http.MethodDelete:
+			app.RemoveRelation(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
 }
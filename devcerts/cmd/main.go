@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"social-network/pkg/certs"
+	"social-network/pkg/internaltoken"
+)
+
+// defaultNames are the certificates docker-compose mounts into the services.
+var defaultNames = []string{"api-gateway", "user-service", "posts-service", "prometheus"}
+
+// devcerts generates a local CA and a certificate for every name given,
+// for docker-compose and tests, and the key of the internal tokens when
+// -token-key is set. Certificates and keys already generated are kept.
+func main() {
+	dir := flag.String("dir", "certs", "directory the CA and the certificates are written to")
+	tokenKey := flag.String("token-key", "", "file the internal token key is written to, none when empty")
+	flag.Parse()
+
+	names := flag.Args()
+	if len(names) == 0 {
+		names = defaultNames
+	}
+
+	err := certs.GenerateDev(*dir, names...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to generate certificates:", err)
+		os.Exit(1)
+	}
+	fmt.Printf("certificates for %v are in %s\n", names, *dir)
+
+	if *tokenKey == "" {
+		return
+	}
+	err = internaltoken.GenerateKeyFile(*tokenKey)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to generate the internal token key:", err)
+		os.Exit(1)
+	}
+	fmt.Println("internal token key is in", *tokenKey)
+}
@@ -0,0 +1,302 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"go.uber.org/fx"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"os"
+	"social-network/pkg/env"
+	"social-network/pkg/logger"
+	"sync"
+	"time"
+)
+
+type Config struct {
+	CertFile string
+	KeyFile  string
+	// CAFile verifies the peer. Servers given a CA require a certificate
+	// signed by it from every client, clients without one trust the system roots
+	CAFile string
+	// ReloadInterval is how often the files are checked for changes
+	ReloadInterval time.Duration
+}
+
+// NewConfig reads the files from <prefix>_CERT_FILE, <prefix>_KEY_FILE and
+// <prefix>_CA_FILE. TLS stays off while no certificate is set.
+func NewConfig(prefix string) Config {
+	return Config{
+		CertFile:       os.Getenv(prefix + "_CERT_FILE"),
+		KeyFile:        os.Getenv(prefix + "_KEY_FILE"),
+		CAFile:         os.Getenv(prefix + "_CA_FILE"),
+		ReloadInterval: env.Duration(prefix+"_RELOAD_INTERVAL", 30*time.Second),
+	}
+}
+
+func (c Config) Enabled() bool {
+	return c.CertFile != ""
+}
+
+// Module provides the *Reloader of the certs.Config the service supplies,
+// nil when TLS is off.
+var Module = fx.Module("certs", fx.Provide(NewReloader))
+
+// Reloader holds the certificate and the CA of a service and picks up new
+// ones when the files change, so rotated certificates are used without a
+// restart. Connections already open keep the certificates they were made with.
+type Reloader struct {
+	cfg Config
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	roots    *x509.CertPool
+	modTimes []time.Time
+}
+
+// NewReloader loads the files and checks them for changes while the app runs.
+// It returns nil when TLS is off, the helpers below then fall back to plaintext.
+func NewReloader(lc fx.Lifecycle, cfg Config) (*Reloader, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+
+	r, err := Load(cfg)
+	if err != nil {
+		logger.Error("failed to load certificates", "cert", cfg.CertFile, "error", err)
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go func() {
+				defer close(done)
+				r.watch(ctx)
+			}()
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			cancel()
+			<-done
+			return nil
+		},
+	})
+	return r, nil
+}
+
+// Load reads the files once, for commands that exit before a reload would matter.
+func Load(cfg Config) (*Reloader, error) {
+	if cfg.KeyFile == "" {
+		return nil, errors.New("certificate is set without a key")
+	}
+	r := &Reloader{cfg: cfg}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Reloader) watch(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !r.changed() {
+			continue
+		}
+		// the files may be caught half written, the next tick retries
+		if err := r.reload(); err != nil {
+			logger.Error("failed to reload certificates", "cert", r.cfg.CertFile, "error", err)
+			continue
+		}
+		logger.Info("reloaded certificates", "cert", r.cfg.CertFile)
+	}
+}
+
+func (r *Reloader) reload() error {
+	modTimes, err := r.stat()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return err
+	}
+
+	var roots *x509.CertPool
+	if r.cfg.CAFile != "" {
+		pem, err := os.ReadFile(r.cfg.CAFile)
+		if err != nil {
+			return err
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates in %s", r.cfg.CAFile)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cert = &cert
+	r.roots = roots
+	r.modTimes = modTimes
+	return nil
+}
+
+func (r *Reloader) changed() bool {
+	modTimes, err := r.stat()
+	if err != nil {
+		return false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for i := range modTimes {
+		if !modTimes[i].Equal(r.modTimes[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Reloader) stat() ([]time.Time, error) {
+	modTimes := make([]time.Time, 0, 3)
+	for _, name := range []string{r.cfg.CertFile, r.cfg.KeyFile, r.cfg.CAFile} {
+		if name == "" {
+			modTimes = append(modTimes, time.Time{})
+			continue
+		}
+		info, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		modTimes = append(modTimes, info.ModTime())
+	}
+	return modTimes, nil
+}
+
+func (r *Reloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, r.roots
+}
+
+// ServerConfig serves the current certificate. With a CA configured the TLS
+// is mutual: clients must present a certificate signed by the current CA.
+func (r *Reloader) ServerConfig() *tls.Config {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			cert, _ := r.current()
+			return cert, nil
+		},
+	}
+	if r.cfg.CAFile == "" {
+		return cfg
+	}
+
+	// ClientCAs is fixed once the config is built, the peer is checked
+	// against the reloaded CA instead
+	cfg.ClientAuth = tls.RequireAnyClientCert
+	cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+		_, roots := r.current()
+		return verify(cs.PeerCertificates, roots, "", x509.ExtKeyUsageClientAuth)
+	}
+	return cfg
+}
+
+// ClientConfig presents the current certificate to servers and verifies them
+// against the current CA, or the system roots when there is none.
+func (r *Reloader) ClientConfig() *tls.Config {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := r.current()
+			return cert, nil
+		},
+	}
+	if r.cfg.CAFile == "" {
+		return cfg
+	}
+
+	// RootCAs is fixed once the config is built, so the built-in verification
+	// is replaced by one against the reloaded CA, host name included
+	cfg.InsecureSkipVerify = true
+	cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+		_, roots := r.current()
+		return verify(cs.PeerCertificates, roots, cs.ServerName, x509.ExtKeyUsageServerAuth)
+	}
+	return cfg
+}
+
+func verify(chain []*x509.Certificate, roots *x509.CertPool, name string, usage x509.ExtKeyUsage) error {
+	if len(chain) == 0 {
+		return errors.New("peer sent no certificate")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := chain[0].Verify(x509.VerifyOptions{
+		DNSName:       name,
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{usage},
+	})
+	return err
+}
+
+// ServerCredentials are the transport credentials of a gRPC server, plaintext
+// when r is nil.
+func ServerCredentials(r *Reloader) credentials.TransportCredentials {
+	if r == nil {
+		return insecure.NewCredentials()
+	}
+	return credentials.NewTLS(r.ServerConfig())
+}
+
+// ClientCredentials are the transport credentials of a gRPC client, plaintext
+// when r is nil.
+func ClientCredentials(r *Reloader) credentials.TransportCredentials {
+	if r == nil {
+		return insecure.NewCredentials()
+	}
+	return credentials.NewTLS(r.ClientConfig())
+}
+
+// Scheme is the scheme of the URLs of a service reached with r.
+func Scheme(r *Reloader) string {
+	if r == nil {
+		return "http"
+	}
+	return "https"
+}
+
+// ServerTLS is the TLS config of an HTTP server, nil when r is nil.
+func ServerTLS(r *Reloader) *tls.Config {
+	if r == nil {
+		return nil
+	}
+	return r.ServerConfig()
+}
+
+// ClientTLS is the TLS config of an HTTP client, nil when r is nil.
+func ClientTLS(r *Reloader) *tls.Config {
+	if r == nil {
+		return nil
+	}
+	return r.ClientConfig()
+}
@@ -0,0 +1,139 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io/fs"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// devValidity is how long the generated certificates are valid.
+const devValidity = 365 * 24 * time.Hour
+
+// GenerateDev writes a local CA to dir as ca.crt and ca.key, and a certificate
+// and key for every name as <name>.crt and <name>.key, signed by that CA. The
+// certificates are valid for the name, localhost and 127.0.0.1, both as server
+// and as client. Files already in dir are kept, so running it again only adds
+// the missing names. It is meant for docker-compose and tests, never production.
+func GenerateDev(dir string, names ...string) error {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return err
+	}
+
+	// certificates signed by a CA that is gone are generated again
+	fresh := false
+	ca, caKey, err := loadDevCA(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		fresh = true
+		ca, caKey, err = newDevCA(dir)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		_, err := os.Stat(filepath.Join(dir, name+".crt"))
+		if err == nil && !fresh {
+			continue
+		}
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return err
+		}
+		template := &x509.Certificate{
+			SerialNumber: serialNumber(),
+			Subject:      pkix.Name{CommonName: name},
+			DNSNames:     []string{name, "localhost"},
+			IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(devValidity),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+		if err != nil {
+			return err
+		}
+		err = writePair(dir, name, der, key)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newDevCA(dir string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber(),
+		Subject:               pkix.Name{CommonName: "social-network dev CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(devValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = writePair(dir, "ca", der, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ca, err := x509.ParseCertificate(der)
+	return ca, key, err
+}
+
+func loadDevCA(dir string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	pair, err := tls.LoadX509KeyPair(filepath.Join(dir, "ca.crt"), filepath.Join(dir, "ca.key"))
+	if err != nil {
+		return nil, nil, err
+	}
+	key, ok := pair.PrivateKey.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, nil, errors.New("ca.key is not an ECDSA key")
+	}
+	ca, err := x509.ParseCertificate(pair.Certificate[0])
+	return ca, key, err
+}
+
+func writePair(dir string, name string, der []byte, key *ecdsa.PrivateKey) error {
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	// the key is written first, a certificate without its key would never be
+	// replaced. Keys are readable by all, the volume they are shared through
+	// is mounted by containers running as other users
+	err = os.WriteFile(filepath.Join(dir, name+".key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0o644)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, name+".crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644)
+}
+
+func serialNumber() *big.Int {
+	serial, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 127))
+	return serial
+}
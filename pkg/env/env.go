@@ -0,0 +1,79 @@
+package env
+
+import (
+	"errors"
+	"github.com/joho/godotenv"
+	"go.uber.org/fx"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Module loads the .env file before anything reads the environment.
+var Module = fx.Module("env", fx.Invoke(Load))
+
+// Load adds the variables of the .env file in the working directory to the
+// environment, variables already set win. A missing file is not an error.
+func Load() error {
+	err := godotenv.Load()
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// String returns the variable, or the fallback when it is unset or empty.
+func String(key string, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+// Secret returns the variable or, when it is unset or empty, the content of
+// the file named by the variable with a _FILE suffix, which is how
+// docker-compose passes the secrets it generates. It is empty when neither
+// is set or the file can't be read, services refuse to start without it.
+func Secret(key string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	path := os.Getenv(key + "_FILE")
+	if path == "" {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// Int returns the variable, or the fallback when it is not a number.
+func Int(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// Float returns the variable, or the fallback when it is not a number.
+func Float(key string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// Duration returns the variable, or the fallback when it is not a duration such as "30s".
+func Duration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
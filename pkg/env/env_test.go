@@ -0,0 +1,39 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSecret(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "key")
+	err := os.WriteFile(file, []byte("from-file\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		file  string
+		want  string
+	}{
+		{name: "variable", value: "from-env", file: file, want: "from-env"},
+		{name: "file when the variable is empty", file: file, want: "from-file"},
+		{name: "missing file", file: filepath.Join(dir, "missing")},
+		{name: "neither"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_SECRET", tt.value)
+			t.Setenv("TEST_SECRET_FILE", tt.file)
+
+			if got := Secret("TEST_SECRET"); got != tt.want {
+				t.Errorf("Secret() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package health
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"social-network/pkg/logger"
+	"sync"
+	"time"
+)
+
+// Check returns an error when the dependency it checks is unusable.
+type Check func(ctx context.Context) error
+
+// Checker runs the readiness checks of the service, all of them concurrently
+// and each bounded by the same timeout.
+type Checker struct {
+	timeout time.Duration
+	checks  map[string]Check
+}
+
+type report struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+func NewChecker(timeout time.Duration, checks map[string]Check) *Checker {
+	return &Checker{
+		timeout: timeout,
+		checks:  checks,
+	}
+}
+
+// Live answers as long as the process serves HTTP, it doesn't look at dependencies
+// so that an outage of one of them doesn't get the service restarted.
+func Live(w http.ResponseWriter, _ *http.Request) {
+	writeReport(w, http.StatusOK, report{Status: "ok"})
+}
+
+// Ready answers 503 until every dependency passes its check.
+func (c *Checker) Ready(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), c.timeout)
+	defer cancel()
+
+	result := report{Status: "ok", Checks: make(map[string]string, len(c.checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := check(ctx)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				logger.ErrorContext(r.Context(), "readiness check failed", "check", name, "error", err)
+				result.Status = "unavailable"
+				result.Checks[name] = err.Error()
+				return
+			}
+			result.Checks[name] = "ok"
+		}()
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	if result.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	writeReport(w, status, result)
+}
+
+func writeReport(w http.ResponseWriter, status int, result report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// Probe asks the running service whether it is ready, it backs the
+// healthcheck command used by docker-compose. tlsConfig is nil for plaintext.
+func Probe(url string, tlsConfig *tls.Config, timeout time.Duration) error {
+	client := &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("service answered %s", resp.Status)
+	}
+	return nil
+}
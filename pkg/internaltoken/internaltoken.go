@@ -0,0 +1,183 @@
+package internaltoken
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"io/fs"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// metadataKey carries the token in the metadata of gRPC calls.
+const metadataKey = "x-internal-token"
+
+// Header carries the token in the requests the api-gateway proxies over HTTP.
+const Header = "X-Internal-Token"
+
+// RoleAnonymous is the principal of proxied requests made for nobody in
+// particular, such as password resets. Their token only proves that they
+// came through the api-gateway.
+const RoleAnonymous = "anonymous"
+
+// ttl bounds how long a token taken from a call can be replayed. A token is
+// signed for every call, so it only has to outlive the retries of that call.
+const ttl = time.Minute
+
+// Principal is the user a service acts for, or the calling service itself
+// when Role is "service". Login is only set for the requests of a user.
+type Principal struct {
+	UserId int32
+	Login  string
+	Role   string
+}
+
+type ctxKey struct{}
+
+// NewContext marks the calls made with the context as made for the principal.
+func NewContext(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, ctxKey{}, principal)
+}
+
+func FromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(ctxKey{}).(Principal)
+	return principal, ok
+}
+
+type claims struct {
+	Login string `json:"login,omitempty"`
+	Role  string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// Signer issues the tokens a service passes to the services it calls. The key
+// is shared by the services only and differs from the one of user tokens,
+// so a token issued to a client is never accepted between the services.
+type Signer struct {
+	key      []byte
+	issuer   string
+	audience string
+}
+
+func NewSigner(key string, issuer string, audience string) (*Signer, error) {
+	if key == "" {
+		return nil, errors.New("internal token key is not set")
+	}
+	return &Signer{key: []byte(key), issuer: issuer, audience: audience}, nil
+}
+
+func (s *Signer) Sign(principal Principal) (string, error) {
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		Login: principal.Login,
+		Role:  principal.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.issuer,
+			Subject:   strconv.Itoa(int(principal.UserId)),
+			Audience:  jwt.ClaimStrings{s.audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	})
+	return token.SignedString(s.key)
+}
+
+// UnaryClientInterceptor signs a token for the principal of the context.
+// Calls without one go out without a token and are rejected by the callee.
+func (s *Signer) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if principal, ok := FromContext(ctx); ok {
+			token, err := s.Sign(principal)
+			if err != nil {
+				return err
+			}
+			ctx = metadata.AppendToOutgoingContext(ctx, metadataKey, token)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// GenerateKeyFile writes a random key to path unless a key is there already,
+// so that every deployment signs with a key of its own. It is how
+// docker-compose gets a key, see devcerts.
+func GenerateKeyFile(path string) error {
+	_, err := os.Stat(path)
+	if err == nil || !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	key := make([]byte, 32)
+	_, err = rand.Read(key)
+	if err != nil {
+		return err
+	}
+	// readable by all like the certificates, the volume it is shared through
+	// is mounted by the services only
+	return os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0o644)
+}
+
+// Verifier accepts the tokens signed for its audience.
+type Verifier struct {
+	key      []byte
+	audience string
+}
+
+func NewVerifier(key string, audience string) (*Verifier, error) {
+	if key == "" {
+		return nil, errors.New("internal token key is not set")
+	}
+	return &Verifier{key: []byte(key), audience: audience}, nil
+}
+
+func (v *Verifier) Verify(tokenString string) (Principal, error) {
+	var c claims
+	_, err := jwt.ParseWithClaims(tokenString, &c, func(*jwt.Token) (any, error) {
+		return v.key, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(v.audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil {
+		return Principal{}, err
+	}
+
+	userId, err := strconv.ParseInt(c.Subject, 10, 32)
+	if err != nil || userId < 0 {
+		return Principal{}, fmt.Errorf("invalid subject %q", c.Subject)
+	}
+	if c.Role == "" {
+		return Principal{}, errors.New("token has no role")
+	}
+	return Principal{UserId: int32(userId), Login: c.Login, Role: c.Role}, nil
+}
+
+// VerifyIncoming verifies the token in the metadata of a served call. A call
+// carrying more than one token is rejected rather than trusting either.
+func (v *Verifier) VerifyIncoming(ctx context.Context) (Principal, error) {
+	return v.verifyOne(metadata.ValueFromIncomingContext(ctx, metadataKey))
+}
+
+// VerifyRequest verifies the token in the header of a served HTTP request.
+func (v *Verifier) VerifyRequest(r *http.Request) (Principal, error) {
+	return v.verifyOne(r.Header.Values(Header))
+}
+
+func (v *Verifier) verifyOne(tokens []string) (Principal, error) {
+	switch len(tokens) {
+	case 0:
+		return Principal{}, errors.New("no internal token")
+	case 1:
+		return v.Verify(tokens[0])
+	default:
+		return Principal{}, errors.New("more than one internal token")
+	}
+}
@@ -0,0 +1,429 @@
+package internaltoken
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	testKey      = "test-key"
+	testAudience = "posts-service"
+)
+
+func newVerifier(t *testing.T) *Verifier {
+	t.Helper()
+	verifier, err := NewVerifier(testKey, testAudience)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return verifier
+}
+
+func newSigner(t *testing.T, key string, audience string) *Signer {
+	t.Helper()
+	signer, err := NewSigner(key, "api-gateway", audience)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer
+}
+
+func sign(t *testing.T, signer *Signer, principal Principal) string {
+	t.Helper()
+	token, err := signer.Sign(principal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// signClaims signs arbitrary claims, for the tokens a Signer never issues.
+func signClaims(t *testing.T, method jwt.SigningMethod, key any, c jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, c).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func validClaims() jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"sub":  "7",
+		"aud":  testAudience,
+		"role": "user",
+		"iat":  now.Unix(),
+		"exp":  now.Add(time.Minute).Unix(),
+	}
+}
+
+func without(c jwt.MapClaims, name string) jwt.MapClaims {
+	delete(c, name)
+	return c
+}
+
+func with(c jwt.MapClaims, name string, value any) jwt.MapClaims {
+	c[name] = value
+	return c
+}
+
+func TestNewSignerAndVerifierRequireKey(t *testing.T) {
+	if _, err := NewSigner("", "api-gateway", testAudience); err == nil {
+		t.Error("NewSigner accepted an empty key")
+	}
+	if _, err := NewVerifier("", testAudience); err == nil {
+		t.Error("NewVerifier accepted an empty key")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	verifier := newVerifier(t)
+	hourAgo := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		token   string
+		want    Principal
+		wantErr bool
+	}{
+		{
+			name:  "signed for the audience",
+			token: sign(t, newSigner(t, testKey, testAudience), Principal{UserId: 7, Role: "user"}),
+			want:  Principal{UserId: 7, Role: "user"},
+		},
+		{
+			name:  "with a login",
+			token: sign(t, newSigner(t, testKey, testAudience), Principal{UserId: 7, Login: "alice", Role: "user"}),
+			want:  Principal{UserId: 7, Login: "alice", Role: "user"},
+		},
+		{
+			name:  "service",
+			token: sign(t, newSigner(t, testKey, testAudience), Principal{Role: "service"}),
+			want:  Principal{UserId: 0, Role: "service"},
+		},
+		{
+			name:    "wrong key",
+			token:   sign(t, newSigner(t, "other-key", testAudience), Principal{UserId: 7, Role: "user"}),
+			wantErr: true,
+		},
+		{
+			name:    "wrong audience",
+			token:   sign(t, newSigner(t, testKey, "user-service"), Principal{UserId: 7, Role: "admin"}),
+			wantErr: true,
+		},
+		{
+			name:    "expired",
+			token:   signClaims(t, jwt.SigningMethodHS256, []byte(testKey), with(with(validClaims(), "iat", hourAgo.Unix()), "exp", hourAgo.Add(time.Minute).Unix())),
+			wantErr: true,
+		},
+		{
+			name:    "missing exp",
+			token:   signClaims(t, jwt.SigningMethodHS256, []byte(testKey), without(validClaims(), "exp")),
+			wantErr: true,
+		},
+		{
+			name:    "issued in the future",
+			token:   signClaims(t, jwt.SigningMethodHS256, []byte(testKey), with(validClaims(), "iat", time.Now().Add(time.Hour).Unix())),
+			wantErr: true,
+		},
+		{
+			name:    "alg none",
+			token:   signClaims(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, validClaims()),
+			wantErr: true,
+		},
+		{
+			name:    "other HMAC alg",
+			token:   signClaims(t, jwt.SigningMethodHS512, []byte(testKey), validClaims()),
+			wantErr: true,
+		},
+		{
+			name:    "missing subject",
+			token:   signClaims(t, jwt.SigningMethodHS256, []byte(testKey), without(validClaims(), "sub")),
+			wantErr: true,
+		},
+		{
+			name:    "subject not a user id",
+			token:   signClaims(t, jwt.SigningMethodHS256, []byte(testKey), with(validClaims(), "sub", "admin")),
+			wantErr: true,
+		},
+		{
+			name:    "negative subject",
+			token:   signClaims(t, jwt.SigningMethodHS256, []byte(testKey), with(validClaims(), "sub", "-1")),
+			wantErr: true,
+		},
+		{
+			name:    "missing role",
+			token:   signClaims(t, jwt.SigningMethodHS256, []byte(testKey), without(validClaims(), "role")),
+			wantErr: true,
+		},
+		{
+			name:    "not a token",
+			token:   "user_id=7;role=admin",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := verifier.Verify(tt.token)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Verify() = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyIncoming(t *testing.T) {
+	verifier := newVerifier(t)
+	signer := newSigner(t, testKey, testAudience)
+	user := sign(t, signer, Principal{UserId: 7, Role: "user"})
+	admin := sign(t, signer, Principal{UserId: 1, Role: "admin"})
+
+	tests := []struct {
+		name    string
+		md      metadata.MD
+		want    Principal
+		wantErr bool
+	}{
+		{
+			name: "one token",
+			md:   metadata.Pairs(metadataKey, user),
+			want: Principal{UserId: 7, Role: "user"},
+		},
+		{
+			name: "plain user_id and role next to the token are ignored",
+			md:   metadata.Pairs(metadataKey, user, "user_id", "1", "role", "admin"),
+			want: Principal{UserId: 7, Role: "user"},
+		},
+		{
+			name:    "no metadata",
+			wantErr: true,
+		},
+		{
+			name:    "bare user_id and role",
+			md:      metadata.Pairs("user_id", "1", "role", "admin"),
+			wantErr: true,
+		},
+		{
+			name:    "two tokens",
+			md:      metadata.Pairs(metadataKey, user, metadataKey, admin),
+			wantErr: true,
+		},
+		{
+			name:    "invalid token",
+			md:      metadata.Pairs(metadataKey, user+"x"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.md != nil {
+				ctx = metadata.NewIncomingContext(ctx, tt.md)
+			}
+
+			got, err := verifier.VerifyIncoming(ctx)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("VerifyIncoming() = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyIncoming() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("VerifyIncoming() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyRequest(t *testing.T) {
+	verifier := newVerifier(t)
+	signer := newSigner(t, testKey, testAudience)
+	user := sign(t, signer, Principal{UserId: 7, Login: "alice", Role: "user"})
+	admin := sign(t, signer, Principal{UserId: 1, Login: "root", Role: "admin"})
+
+	tests := []struct {
+		name    string
+		headers map[string][]string
+		want    Principal
+		wantErr bool
+	}{
+		{
+			name:    "one token",
+			headers: map[string][]string{Header: {user}},
+			want:    Principal{UserId: 7, Login: "alice", Role: "user"},
+		},
+		{
+			name:    "plain login and role next to the token are ignored",
+			headers: map[string][]string{Header: {user}, "Login": {"root"}, "Role": {"admin"}},
+			want:    Principal{UserId: 7, Login: "alice", Role: "user"},
+		},
+		{
+			name:    "bare login and role",
+			headers: map[string][]string{"Login": {"root"}, "Role": {"admin"}},
+			wantErr: true,
+		},
+		{
+			name:    "two tokens",
+			headers: map[string][]string{Header: {user, admin}},
+			wantErr: true,
+		},
+		{
+			name:    "token for another audience",
+			headers: map[string][]string{Header: {sign(t, newSigner(t, testKey, "user-service"), Principal{Role: "service"})}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/user-profile", nil)
+			for name, values := range tt.headers {
+				for _, value := range values {
+					r.Header.Add(name, value)
+				}
+			}
+
+			got, err := verifier.VerifyRequest(r)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("VerifyRequest() = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyRequest() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("VerifyRequest() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	verifier := newVerifier(t)
+	interceptor := newSigner(t, testKey, testAudience).UnaryClientInterceptor()
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		want      Principal
+		wantToken bool
+	}{
+		{
+			name:      "principal in the context",
+			ctx:       NewContext(context.Background(), Principal{UserId: 7, Role: "moderator"}),
+			want:      Principal{UserId: 7, Role: "moderator"},
+			wantToken: true,
+		},
+		{
+			name: "no principal",
+			ctx:  context.Background(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent metadata.MD
+			invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				sent, _ = metadata.FromOutgoingContext(ctx)
+				return nil
+			}
+
+			err := interceptor(tt.ctx, "/PostsService/GetPostById", nil, nil, nil, invoker)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tokens := sent.Get(metadataKey)
+			if !tt.wantToken {
+				if len(tokens) != 0 {
+					t.Fatalf("sent %d tokens, want none", len(tokens))
+				}
+				return
+			}
+			if len(tokens) != 1 {
+				t.Fatalf("sent %d tokens, want 1", len(tokens))
+			}
+			got, err := verifier.Verify(tokens[0])
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("signed %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignSetsClaims(t *testing.T) {
+	token := sign(t, newSigner(t, testKey, testAudience), Principal{UserId: 42, Login: "root", Role: "admin"})
+
+	var c claims
+	_, err := jwt.ParseWithClaims(token, &c, func(*jwt.Token) (any, error) {
+		return []byte(testKey), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Subject != "42" || c.Login != "root" || c.Role != "admin" || c.Issuer != "api-gateway" {
+		t.Errorf("claims = %+v", c)
+	}
+	if ttlLeft := time.Until(c.ExpiresAt.Time); ttlLeft <= 0 || ttlLeft > ttl {
+		t.Errorf("expires in %v, want within %v", ttlLeft, ttl)
+	}
+}
+
+func TestGenerateKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "internal-token.key")
+
+	err := GenerateKeyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(first)))
+	if err != nil || len(key) != 32 {
+		t.Fatalf("key = %q, want 32 bytes in base64", first)
+	}
+
+	err = GenerateKeyFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(second) != string(first) {
+		t.Error("an existing key was replaced")
+	}
+}
@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"log/slog"
+	"os"
+	"social-network/pkg/requestid"
+	"strings"
+)
+
+// redacted replaces the values of attributes that may hold credentials.
+const redacted = "[REDACTED]"
+
+var (
+	logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+)
+
+// InitLogger logs JSON to stdout at the level from LOG_LEVEL (debug, info,
+// warn or error, info by default). Records logged with a context carry its
+// request id and trace id.
+func InitLogger() {
+	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level:       parseLevel(os.Getenv("LOG_LEVEL")),
+		ReplaceAttr: redact,
+	})
+	logger = slog.New(contextHandler{handler})
+}
+
+func Debug(msg string, args ...any) {
+	logger.Debug(msg, args...)
+}
+
+func Info(msg string, args ...any) {
+	logger.Info(msg, args...)
+}
+
+func Warn(msg string, args ...any) {
+	logger.Warn(msg, args...)
+}
+
+func Error(msg string, args ...any) {
+	logger.Error(msg, args...)
+}
+
+func DebugContext(ctx context.Context, msg string, args ...any) {
+	logger.DebugContext(ctx, msg, args...)
+}
+
+func InfoContext(ctx context.Context, msg string, args ...any) {
+	logger.InfoContext(ctx, msg, args...)
+}
+
+func WarnContext(ctx context.Context, msg string, args ...any) {
+	logger.WarnContext(ctx, msg, args...)
+}
+
+func ErrorContext(ctx context.Context, msg string, args ...any) {
+	logger.ErrorContext(ctx, msg, args...)
+}
+
+func parseLevel(value string) slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// redact hides the values of attributes whose keys name a password, a token,
+// a secret or an auth header, wherever they are nested.
+func redact(_ []string, attr slog.Attr) slog.Attr {
+	key := strings.ToLower(attr.Key)
+	switch {
+	case strings.Contains(key, "password"),
+		strings.Contains(key, "token"),
+		strings.Contains(key, "secret"),
+		key == "authorization",
+		key == "cookie":
+		return slog.String(attr.Key, redacted)
+	}
+	return attr
+}
+
+// contextHandler adds the request id and the trace of the context to every record.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := requestid.FromContext(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", span.TraceID().String()),
+			slog.String("span_id", span.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// Module sets up logging first thing when the app is built.
+var Module = fx.Module("logger", fx.Invoke(InitLogger))
@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/bun/extra/bunotel"
+	"go.uber.org/fx"
+	"social-network/pkg/health"
+	"social-network/pkg/logger"
+	"social-network/pkg/metrics"
+	"time"
+)
+
+type Config struct {
+	Host     string
+	Port     int
+	Db       string
+	User     string
+	Password string
+
+	// ConnectAttempts and ConnectBackoff bound the pings while the database comes up,
+	// the backoff doubles after every failed attempt up to MaxBackoff
+	ConnectAttempts int
+	ConnectBackoff  time.Duration
+	MaxBackoff      time.Duration
+
+	// TraceQueries records a span for every query. Only worth it when the
+	// repositories pass on the context of the request.
+	TraceQueries bool
+}
+
+// Schema is what a service keeps in its database.
+type Schema struct {
+	Models []any
+	// Migrations add columns and indexes introduced after a table was first created,
+	// CREATE TABLE IF NOT EXISTS leaves existing tables untouched
+	Migrations []string
+}
+
+// Module provides the *bun.DB of a service, which supplies the Config and the Schema.
+var Module = fx.Module("postgres", fx.Provide(New))
+
+// New connects to postgres and applies the schema. It fails when the database
+// does not come up within the configured attempts, so fx aborts startup instead
+// of serving requests without a database. The pool is closed on stop, after the
+// servers registered later have drained.
+func New(lc fx.Lifecycle, cfg Config, schema Schema) (*bun.DB, error) {
+	dsn := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable",
+		cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Db)
+
+	sqldb, err := sql.Open("pgx", dsn)
+	if err != nil {
+		logger.Error("init db failed", "error", err)
+		return nil, err
+	}
+
+	db := bun.NewDB(sqldb, pgdialect.New())
+	db.AddQueryHook(metrics.QueryHook{})
+	if cfg.TraceQueries {
+		db.AddQueryHook(bunotel.NewQueryHook(bunotel.WithDBName(cfg.Db)))
+	}
+	if err = migrate(db, cfg, schema); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(_ context.Context) error {
+			logger.Info("closing db connections")
+			return db.Close()
+		},
+	})
+
+	logger.Info("init db success", "host", cfg.Host, "port", cfg.Port, "db", cfg.Db)
+	return db, nil
+}
+
+// Check is the readiness check of the database.
+func Check(db *bun.DB) health.Check {
+	return db.PingContext
+}
+
+func migrate(db *bun.DB, cfg Config, schema Schema) error {
+	if err := ping(db, cfg); err != nil {
+		return err
+	}
+
+	for _, model := range schema.Models {
+		_, err := db.NewCreateTable().
+			IfNotExists().
+			Model(model).
+			Exec(context.Background())
+		if err != nil {
+			logger.Error("create table failed", "error", err)
+			return err
+		}
+	}
+
+	for _, migration := range schema.Migrations {
+		_, err := db.ExecContext(context.Background(), migration)
+		if err != nil {
+			logger.Error("migration failed", "error", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// ping waits for the database with exponential backoff, postgres usually
+// accepts connections a few seconds after its container is started
+func ping(db *bun.DB, cfg Config) error {
+	attempts := max(cfg.ConnectAttempts, 1)
+	backoff := cfg.ConnectBackoff
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.MaxBackoff)
+		err = db.PingContext(ctx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if attempt == attempts {
+			break
+		}
+
+		logger.Error("db ping failed", "attempt", attempt, "attempts", attempts, "retry_in", backoff, "error", err)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, cfg.MaxBackoff)
+	}
+	return fmt.Errorf("db is unavailable after %d attempts: %w", attempts, err)
+}
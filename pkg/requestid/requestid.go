@@ -0,0 +1,106 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"net/http"
+	"strings"
+)
+
+// Header carries the request id between the gateway, user-service and the client.
+const Header = "X-Request-ID"
+
+// metadataKey carries the request id in the metadata of gRPC calls.
+const metadataKey = "x-request-id"
+
+// maxLength bounds ids taken from requests, the gateway issues far shorter ones.
+const maxLength = 64
+
+type ctxKey struct{}
+
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext returns the request id of the context, empty when there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKey{}).(string)
+	return id
+}
+
+func New() string {
+	return strings.ToLower(rand.Text())
+}
+
+// Issue gives every request a new id, it is used by the gateway. An id sent
+// by the client is replaced, ids are only trusted once the gateway has issued them.
+func Issue(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := New()
+		r.Header.Set(Header, id)
+		w.Header().Set(Header, id)
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), id)))
+	})
+}
+
+// Middleware keeps the id the gateway gave the request, requests made
+// around the gateway get a new one.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := valid(r.Header.Get(Header))
+		w.Header().Set(Header, id)
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), id)))
+	})
+}
+
+// UnaryServerInterceptor takes the request id from the metadata of the call.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var id string
+		if values := metadata.ValueFromIncomingContext(ctx, metadataKey); len(values) > 0 {
+			id = values[0]
+		}
+		return handler(NewContext(ctx, valid(id)), req)
+	}
+}
+
+// UnaryClientInterceptor passes the request id of the context on to the called service.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if id := FromContext(ctx); id != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, metadataKey, id)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// Transport sets the request id header on the requests made by an HTTP client.
+type Transport struct {
+	Base http.RoundTripper
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	id := FromContext(req.Context())
+	if id == "" {
+		return t.Base.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set(Header, id)
+	return t.Base.RoundTrip(req)
+}
+
+// valid returns the id when it is safe to log, a new id otherwise.
+func valid(id string) string {
+	if id == "" || len(id) > maxLength {
+		return New()
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return New()
+		}
+	}
+	return id
+}
@@ -0,0 +1,98 @@
+package serving
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	"net"
+	"net/http"
+	"social-network/pkg/logger"
+	"social-network/pkg/metrics"
+	"social-network/pkg/requestid"
+)
+
+// HTTPModule serves the *http.Server the service provides.
+var HTTPModule = fx.Module("http-server", fx.Invoke(HTTP))
+
+// HTTP opens the listener while the app starts, so a taken port aborts
+// startup, and drains in-flight requests on stop. The server speaks TLS
+// when it has a TLSConfig.
+func HTTP(lc fx.Lifecycle, srv *http.Server) {
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			lis, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				logger.Error("failed to listen", "addr", srv.Addr, "error", err)
+				return err
+			}
+			if srv.TLSConfig != nil {
+				lis = tls.NewListener(lis, srv.TLSConfig)
+			}
+
+			go func() {
+				logger.Info("starting server", "addr", srv.Addr, "tls", srv.TLSConfig != nil)
+				if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					logger.Error("server stopped", "error", err)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			logger.Info("shutting down server", "addr", srv.Addr)
+			return srv.Shutdown(ctx)
+		},
+	})
+}
+
+// NewGRPCServer returns a gRPC server that traces calls, health checks aside,
+// and records their request id and metrics.
+func NewGRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	opts = append([]grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithFilter(filters.Not(filters.HealthCheck())))),
+		grpc.ChainUnaryInterceptor(
+			requestid.UnaryServerInterceptor(),
+			metrics.UnaryServerInterceptor(),
+		),
+	}, opts...)
+	return grpc.NewServer(opts...)
+}
+
+// GRPC serves srv on addr like HTTP does. GracefulStop waits for every open
+// stream, so they are cut off once fx gives up on stopping.
+func GRPC(lc fx.Lifecycle, addr string, srv *grpc.Server) {
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			lis, err := net.Listen("tcp", addr)
+			if err != nil {
+				logger.Error("failed to listen", "addr", addr, "error", err)
+				return err
+			}
+
+			go func() {
+				logger.Info("starting grpc server", "addr", addr)
+				if err := srv.Serve(lis); err != nil {
+					logger.Error("grpc server stopped", "error", err)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			stopped := make(chan struct{})
+			go func() {
+				srv.GracefulStop()
+				close(stopped)
+			}()
+
+			select {
+			case <-stopped:
+			case <-ctx.Done():
+				srv.Stop()
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,109 @@
+package throttle
+
+import (
+	"sync"
+	"time"
+)
+
+// Throttle counts failed attempts per key and makes the key wait
+// exponentially longer after every failure beyond the free ones. The count
+// is never reset by a success, since a key such as an IP address is shared
+// by legitimate and hostile attempts alike. It decays instead: one failure
+// is forgotten for every decay interval without a new one.
+type Throttle struct {
+	mu           sync.Mutex
+	attempts     map[string]*attempt
+	freeAttempts int
+	baseDelay    time.Duration
+	maxDelay     time.Duration
+	decay        time.Duration
+	lastSweep    time.Time
+}
+
+type attempt struct {
+	failures     int
+	blockedUntil time.Time
+	lastFailure  time.Time
+}
+
+func New(freeAttempts int, baseDelay time.Duration, maxDelay time.Duration, decay time.Duration) *Throttle {
+	return &Throttle{
+		attempts:     make(map[string]*attempt),
+		freeAttempts: freeAttempts,
+		baseDelay:    baseDelay,
+		maxDelay:     maxDelay,
+		decay:        decay,
+		lastSweep:    time.Now(),
+	}
+}
+
+// Allow reports whether the key may make an attempt now, and if not, how long it has to wait.
+func (t *Throttle) Allow(key string) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	a, ok := t.attempts[key]
+	if !ok {
+		return 0, true
+	}
+	wait := time.Until(a.blockedUntil)
+	if wait > 0 {
+		return wait, false
+	}
+	return 0, true
+}
+
+func (t *Throttle) Failure(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	t.sweep(now)
+
+	a, ok := t.attempts[key]
+	if !ok {
+		a = &attempt{}
+		t.attempts[key] = a
+	}
+	a.failures = t.remaining(a, now) + 1
+	a.lastFailure = now
+	if delay := Delay(a.failures, t.freeAttempts, t.baseDelay, t.maxDelay); delay > 0 {
+		a.blockedUntil = now.Add(delay)
+	}
+}
+
+// remaining is the number of failures of a still counted at now.
+func (t *Throttle) remaining(a *attempt, now time.Time) int {
+	return max(a.failures-int(now.Sub(a.lastFailure)/t.decay), 0)
+}
+
+// sweep forgets keys whose failures have all decayed, so the map doesn't grow unbounded.
+func (t *Throttle) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < t.decay {
+		return
+	}
+	t.lastSweep = now
+
+	for key, a := range t.attempts {
+		if t.remaining(a, now) == 0 && !now.Before(a.blockedUntil) {
+			delete(t.attempts, key)
+		}
+	}
+}
+
+// Delay returns how long to wait after the given number of consecutive failures:
+// nothing for the first freeAttempts, then baseDelay doubled for every further failure, up to maxDelay.
+func Delay(failures int, freeAttempts int, baseDelay time.Duration, maxDelay time.Duration) time.Duration {
+	if failures <= freeAttempts {
+		return 0
+	}
+
+	delay := baseDelay
+	for i := freeAttempts + 1; i < failures; i++ {
+		delay *= 2
+		if delay >= maxDelay {
+			return maxDelay
+		}
+	}
+	return min(delay, maxDelay)
+}
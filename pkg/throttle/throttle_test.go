@@ -0,0 +1,97 @@
+package throttle
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		want     time.Duration
+	}{
+		{name: "no failures", failures: 0, want: 0},
+		{name: "last free attempt", failures: 3, want: 0},
+		{name: "first throttled failure", failures: 4, want: time.Second},
+		{name: "doubles", failures: 5, want: 2 * time.Second},
+		{name: "doubles again", failures: 7, want: 8 * time.Second},
+		{name: "capped", failures: 10, want: 30 * time.Second},
+		{name: "no overflow far past the cap", failures: 200, want: 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Delay(tt.failures, 3, time.Second, 30*time.Second)
+			if got != tt.want {
+				t.Errorf("Delay(%d) = %v, want %v", tt.failures, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemainingDecays(t *testing.T) {
+	th := New(3, time.Second, time.Minute, time.Minute)
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		want    int
+	}{
+		{name: "just failed", elapsed: 0, want: 5},
+		{name: "within one interval", elapsed: 59 * time.Second, want: 5},
+		{name: "one interval", elapsed: time.Minute, want: 4},
+		{name: "three intervals", elapsed: 3*time.Minute + time.Second, want: 2},
+		{name: "all decayed", elapsed: time.Hour, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &attempt{failures: 5, lastFailure: now.Add(-tt.elapsed)}
+			if got := th.remaining(a, now); got != tt.want {
+				t.Errorf("remaining = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFailureBlocksAfterFreeAttempts(t *testing.T) {
+	th := New(2, time.Hour, time.Hour, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		th.Failure("key")
+		if _, ok := th.Allow("key"); !ok {
+			t.Fatalf("blocked after %d failures", i+1)
+		}
+	}
+
+	th.Failure("key")
+	wait, ok := th.Allow("key")
+	if ok || wait <= 0 || wait > time.Hour {
+		t.Fatalf("Allow = %v, %v, want blocked for up to an hour", wait, ok)
+	}
+	if _, ok := th.Allow("other"); !ok {
+		t.Error("another key is blocked")
+	}
+}
+
+func TestSweepForgetsDecayedKeys(t *testing.T) {
+	th := New(3, time.Second, time.Minute, time.Minute)
+	now := time.Now()
+	th.attempts["decayed"] = &attempt{failures: 2, lastFailure: now.Add(-time.Hour)}
+	th.attempts["recent"] = &attempt{failures: 2, lastFailure: now}
+	th.attempts["blocked"] = &attempt{failures: 0, lastFailure: now.Add(-time.Hour), blockedUntil: now.Add(5 * time.Minute)}
+
+	th.sweep(now.Add(time.Minute))
+
+	if _, ok := th.attempts["decayed"]; ok {
+		t.Error("decayed key is kept")
+	}
+	if _, ok := th.attempts["recent"]; !ok {
+		t.Error("key with failures left is forgotten")
+	}
+	if _, ok := th.attempts["blocked"]; !ok {
+		t.Error("key that is still blocked is forgotten")
+	}
+}
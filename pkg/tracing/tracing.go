@@ -0,0 +1,152 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.uber.org/fx"
+	"io"
+	"net/http"
+	"os"
+	"social-network/pkg/env"
+	"social-network/pkg/logger"
+)
+
+type Config struct {
+	// Exporter is "otlp", "stdout" or "none". The stdout exporter writes
+	// to File instead when it is set
+	Exporter    string
+	Endpoint    string
+	File        string
+	SampleRatio float64
+}
+
+func NewConfig() Config {
+	return Config{
+		Exporter:    env.String("TRACE_EXPORTER", "none"),
+		Endpoint:    env.String("OTEL_EXPORTER_OTLP_ENDPOINT", "otel-collector:4317"),
+		File:        os.Getenv("TRACE_FILE"),
+		SampleRatio: env.Float("TRACE_SAMPLE_RATIO", 1),
+	}
+}
+
+// Module sets up tracing for the service. It has to come before the servers
+// so that its stop hook runs after them and flushes the spans of the drained requests.
+func Module(serviceName string) fx.Option {
+	return fx.Module("tracing",
+		fx.Provide(NewConfig),
+		fx.Invoke(func(lc fx.Lifecycle, cfg Config) error {
+			return Start(lc, serviceName, cfg)
+		}),
+	)
+}
+
+// Start installs the global tracer provider and the W3C trace context
+// propagator, spans are reported under serviceName.
+func Start(lc fx.Lifecycle, serviceName string, cfg Config) error {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	exporter, closer, err := newExporter(cfg)
+	if err != nil {
+		logger.Error("failed to create trace exporter", "error", err)
+		return err
+	}
+	if exporter == nil {
+		// spans are not recorded, the incoming trace context is still passed on
+		return nil
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+	))
+	if err != nil {
+		return err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	logger.Info("exporting traces", "exporter", cfg.Exporter)
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			err := provider.Shutdown(ctx)
+			if closer != nil {
+				_ = closer.Close()
+			}
+			return err
+		},
+	})
+	return nil
+}
+
+func newExporter(cfg Config) (sdktrace.SpanExporter, io.Closer, error) {
+	switch cfg.Exporter {
+	case "otlp":
+		exporter, err := otlptracegrpc.New(context.Background(),
+			otlptracegrpc.WithEndpoint(cfg.Endpoint),
+			otlptracegrpc.WithInsecure(),
+		)
+		return exporter, nil, err
+	case "stdout":
+		if cfg.File == "" {
+			exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+			return exporter, nil, err
+		}
+
+		file, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			_ = file.Close()
+			return nil, nil, err
+		}
+		return exporter, file, nil
+	case "none", "":
+		return nil, nil, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+}
+
+// Middleware starts a server span for every request, named after the pattern
+// mux routes it to. Probes and scrapes are left out. The gateway passes
+// otelhttp.WithPublicEndpoint so that a trace context sent by a client is only
+// linked, the services behind it continue the trace the gateway started.
+func Middleware(mux *http.ServeMux, next http.Handler, opts ...otelhttp.Option) http.Handler {
+	opts = append([]otelhttp.Option{
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			_, route := mux.Handler(r)
+			if route == "" {
+				route = "unmatched"
+			}
+			return r.Method + " " + route
+		}),
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			return !untracedPaths[r.URL.Path]
+		}),
+	}, opts...)
+	return otelhttp.NewHandler(next, "http.server", opts...)
+}
+
+var untracedPaths = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+	"/metrics": true,
+}
@@ -0,0 +1,36 @@
+package validation
+
+import (
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// StatusError carries the failed fields to gRPC clients as BadRequest details.
+func StatusError(err *ValidationError) error {
+	badRequest := &errdetails.BadRequest{}
+	for _, field := range err.Fields {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       field.Field,
+			Description: field.Message,
+		})
+	}
+
+	st, detailsErr := status.New(codes.InvalidArgument, err.Error()).WithDetails(badRequest)
+	if detailsErr != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return st.Err()
+}
+
+// FromBadRequest is the ValidationError a StatusError was made from.
+func FromBadRequest(badRequest *errdetails.BadRequest) *ValidationError {
+	validationErr := &ValidationError{}
+	for _, violation := range badRequest.GetFieldViolations() {
+		validationErr.Fields = append(validationErr.Fields, FieldError{
+			Field:   violation.GetField(),
+			Message: violation.GetDescription(),
+		})
+	}
+	return validationErr
+}
@@ -0,0 +1,76 @@
+package validation
+
+import (
+	"slices"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestBadRequestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []FieldError
+	}{
+		{
+			name: "one field",
+			fields: []FieldError{
+				{Field: "login", Message: "is required"},
+			},
+		},
+		{
+			name: "several fields keep their order",
+			fields: []FieldError{
+				{Field: "password", Message: "must be at least 8 characters long"},
+				{Field: "email", Message: "must be a valid email address"},
+				{Field: "tags[0]", Message: "may contain only letters, digits, '_' and '-'"},
+			},
+		},
+		{
+			name: "no fields",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := StatusError(&ValidationError{Fields: tt.fields})
+
+			// send the status through its wire form like a gRPC call does
+			data, marshalErr := proto.Marshal(status.Convert(err).Proto())
+			if marshalErr != nil {
+				t.Fatal(marshalErr)
+			}
+			var wire spb.Status
+			if unmarshalErr := proto.Unmarshal(data, &wire); unmarshalErr != nil {
+				t.Fatal(unmarshalErr)
+			}
+			st := status.FromProto(&wire)
+
+			if st.Code() != codes.InvalidArgument {
+				t.Errorf("code = %v, want %v", st.Code(), codes.InvalidArgument)
+			}
+			if st.Message() != "Validation failed" {
+				t.Errorf("message = %q", st.Message())
+			}
+
+			var badRequest *errdetails.BadRequest
+			for _, detail := range st.Details() {
+				if br, ok := detail.(*errdetails.BadRequest); ok {
+					badRequest = br
+				}
+			}
+			if badRequest == nil {
+				t.Fatalf("details = %v, want a BadRequest", st.Details())
+			}
+
+			got := FromBadRequest(badRequest)
+			if !slices.Equal(got.Fields, tt.fields) {
+				t.Errorf("fields = %v, want %v", got.Fields, tt.fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	loginRegexp   = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+	hashtagRegexp = regexp.MustCompile(`^[\p{L}\p{N}_-]+$`)
+	validate      = newValidator()
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every field that failed validation, by json name.
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+}
+
+func (ve *ValidationError) Error() string {
+	return "Validation failed"
+}
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(jsonName)
+	_ = v.RegisterValidation("login", func(fl validator.FieldLevel) bool {
+		return loginRegexp.MatchString(fl.Field().String())
+	})
+	_ = v.RegisterValidation("birthday", func(fl validator.FieldLevel) bool {
+		date, err := time.Parse(time.DateOnly, fl.Field().String())
+		return err == nil && date.Year() >= 1900 && date.Before(time.Now())
+	})
+	_ = v.RegisterValidation("hashtag", func(fl validator.FieldLevel) bool {
+		return hashtagRegexp.MatchString(fl.Field().String())
+	})
+	return v
+}
+
+// Struct validates every field of s against its `validate` tags.
+func Struct(s any) error {
+	return toValidationError(validate.Struct(s))
+}
+
+// StructPartial validates only the fields of s whose json names are listed.
+func StructPartial(s any, fields ...string) error {
+	t := reflect.Indirect(reflect.ValueOf(s)).Type()
+
+	names := make([]string, 0, len(fields))
+	for i := 0; i < t.NumField(); i++ {
+		for _, field := range fields {
+			if jsonName(t.Field(i)) == field {
+				names = append(names, t.Field(i).Name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return toValidationError(validate.StructPartial(s, names...))
+}
+
+func toValidationError(err error) error {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+
+	result := &ValidationError{}
+	for _, fieldErr := range validationErrs {
+		result.Fields = append(result.Fields, FieldError{
+			Field:   fieldErr.Field(),
+			Message: message(fieldErr),
+		})
+	}
+	return result
+}
+
+func message(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		if fieldErr.Kind() == reflect.Slice {
+			return fmt.Sprintf("must contain at least %s items", fieldErr.Param())
+		}
+		return fmt.Sprintf("must be at least %s characters long", fieldErr.Param())
+	case "max":
+		if fieldErr.Kind() == reflect.Slice {
+			return fmt.Sprintf("must contain at most %s items", fieldErr.Param())
+		}
+		return fmt.Sprintf("must be at most %s characters long", fieldErr.Param())
+	case "email":
+		return "must be a valid email address"
+	case "e164":
+		return "must be a phone number in international format, e.g. +79991234567"
+	case "login":
+		return "may contain only latin letters, digits, '.', '_' and '-'"
+	case "birthday":
+		return "must be a past date in YYYY-MM-DD format"
+	case "hashtag":
+		return "may contain only letters, digits, '_' and '-'"
+	}
+	return "is invalid"
+}
+
+func jsonName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
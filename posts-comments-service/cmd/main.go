@@ -1,22 +1,40 @@
 package main
 
 import (
+	"fmt"
 	"go.uber.org/fx"
+	"os"
+	"social-network/pkg/certs"
+	"social-network/pkg/env"
+	"social-network/pkg/logger"
+	"social-network/pkg/tracing"
 	"social-network/posts-comments-service/internal/app"
+	"social-network/posts-comments-service/internal/auth"
 	"social-network/posts-comments-service/internal/config"
 	"social-network/posts-comments-service/internal/db"
-	"social-network/posts-comments-service/internal/logger"
+	"social-network/posts-comments-service/internal/health"
 	"social-network/posts-comments-service/internal/repository"
 	"social-network/posts-comments-service/internal/server"
 	"social-network/posts-comments-service/internal/service"
+	"time"
 )
 
 func main() {
-	logger.InitLogger()
+	if len(os.Args) > 1 && os.Args[1] == "healthcheck" {
+		healthcheck()
+		return
+	}
+
 	addOpts := fx.Options(
+		env.Module,
+		logger.Module,
+		tracing.Module("posts-comments-service"),
+		certs.Module,
+		db.Module,
 		fx.Provide(
 			config.NewConfig,
-			db.InitDb,
+			config.NewInternalTLS,
+			auth.NewVerifier,
 			repository.NewPostRepository,
 			func(repo *repository.PostRepository) service.Repository {
 				return repo
@@ -26,10 +44,33 @@ func main() {
 				return service
 			},
 			app.NewServer,
+			health.NewServer,
 		),
 		fx.Invoke(
 			server.RunServer,
+			health.InvokeHealthChecks,
+			server.RunMetricsServer,
 		),
 	)
 	fx.New(addOpts).Run()
 }
+
+// healthcheck exits with a non-zero code unless the running service is serving.
+func healthcheck() {
+	cfg := config.NewConfig()
+	var internal *certs.Reloader
+	if cfg.InternalTLS.Enabled() {
+		var err error
+		internal, err = certs.Load(cfg.InternalTLS)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "unhealthy:", err)
+			os.Exit(1)
+		}
+	}
+
+	err := health.Probe(cfg.ServHost+cfg.ServAddr, certs.ClientCredentials(internal), 2*time.Second)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "unhealthy:", err)
+		os.Exit(1)
+	}
+}
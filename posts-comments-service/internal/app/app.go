@@ -3,20 +3,26 @@ package app
 import (
 	"context"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
-	"social-network/posts-comments-service/internal/logger"
+	"social-network/pkg/logger"
+	"social-network/posts-comments-service/internal/auth"
 	pb "social-network/protos"
-	"strconv"
 )
 
 type Service interface {
-	AddPost(post *pb.PostEssential, userId int32) error
-	DeletePost(postId int32) error
-	UpdatePost(post *pb.PostWithNoUser) error
-	GetPostById(postId int32) (*pb.Post, error)
-	GetAllPosts(pagination *pb.Pagination, userId int32) (*pb.AllPosts, error)
+	AddPost(ctx context.Context, post *pb.PostEssential, userId int32) error
+	DeletePost(ctx context.Context, postId int32, caller auth.Caller) error
+	UpdatePost(ctx context.Context, req *pb.UpdatePostRequest, caller auth.Caller) error
+	GetPostById(ctx context.Context, postId int32, caller auth.Caller) (*pb.Post, error)
+	GetAllPosts(ctx context.Context, pagination *pb.Pagination, userId int32) (*pb.AllPosts, error)
+	HidePost(ctx context.Context, req *pb.HidePostRequest, caller auth.Caller) error
+	ReportPost(ctx context.Context, req *pb.ReportPostRequest, caller auth.Caller) error
+	ListReports(ctx context.Context, req *pb.ListReportsRequest, caller auth.Caller) (*pb.ReportList, error)
+	UpdateReportStatus(ctx context.Context, req *pb.UpdateReportStatusRequest, caller auth.Caller) (*pb.Report, error)
+	DeleteUserContent(ctx context.Context, req *pb.DeleteUserContentRequest, caller auth.Caller) error
+	ExportUserContent(ctx context.Context, caller auth.Caller) (*pb.UserContent, error)
+	GetUserStats(ctx context.Context, req *pb.UserStatsRequest) (*pb.UserStats, error)
 }
 
 type Server struct {
@@ -31,47 +37,174 @@ func NewServer(service Service) *Server {
 }
 
 func (s *Server) AddPost(ctx context.Context, post *pb.PostEssential) (*emptypb.Empty, error) {
-	logger.Info("add post called")
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return nil, status.Error(codes.Unauthenticated, "no metadata")
-	}
-	userIDs := md.Get("user_id")
-	if len(userIDs) == 0 {
-		return nil, status.Error(codes.Unauthenticated, "no user_id")
+	logger.InfoContext(ctx, "add post called")
+	caller, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	userId, _ := strconv.Atoi(userIDs[0])
-	return &emptypb.Empty{}, s.service.AddPost(post, int32(userId))
+	err = s.service.AddPost(ctx, post, caller.UserId)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &emptypb.Empty{}, nil
 }
 
-func (s *Server) DeletePost(_ context.Context, post *pb.PostId) (*emptypb.Empty, error) {
-	logger.Info("delete post called")
-	return &emptypb.Empty{}, s.service.DeletePost(post.PostId)
+func (s *Server) DeletePost(ctx context.Context, post *pb.PostId) (*emptypb.Empty, error) {
+	logger.InfoContext(ctx, "delete post called")
+	caller, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.service.DeletePost(ctx, post.PostId, caller)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &emptypb.Empty{}, nil
 }
 
-func (s *Server) UpdatePost(_ context.Context, post *pb.PostWithNoUser) (*emptypb.Empty, error) {
-	logger.Info("update post called")
-	return &emptypb.Empty{}, s.service.UpdatePost(post)
+func (s *Server) UpdatePost(ctx context.Context, req *pb.UpdatePostRequest) (*emptypb.Empty, error) {
+	logger.InfoContext(ctx, "update post called")
+	caller, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.service.UpdatePost(ctx, req, caller)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &emptypb.Empty{}, nil
 }
 
-func (s *Server) GetPostById(_ context.Context, id *pb.PostId) (*pb.Post, error) {
-	logger.Info("get post by id called")
-	return s.service.GetPostById(id.PostId)
+func (s *Server) GetPostById(ctx context.Context, id *pb.PostId) (*pb.Post, error) {
+	logger.InfoContext(ctx, "get post by id called")
+	caller, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := s.service.GetPostById(ctx, id.PostId, caller)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return post, nil
 }
 
 func (s *Server) GetAllPostsPaginated(ctx context.Context, pagination *pb.Pagination) (*pb.AllPosts, error) {
-	logger.Info("get all posts paginated")
-	md, ok := metadata.FromIncomingContext(ctx)
-	if !ok {
-		return nil, status.Error(codes.Unauthenticated, "no metadata")
+	logger.InfoContext(ctx, "get all posts paginated")
+	caller, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.service.GetAllPosts(ctx, pagination, caller.UserId)
+}
+
+func (s *Server) HidePost(ctx context.Context, req *pb.HidePostRequest) (*emptypb.Empty, error) {
+	logger.InfoContext(ctx, "hide post called")
+	caller, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.service.HidePost(ctx, req, caller)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) ReportPost(ctx context.Context, req *pb.ReportPostRequest) (*emptypb.Empty, error) {
+	logger.InfoContext(ctx, "report post called")
+	caller, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.service.ReportPost(ctx, req, caller)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *Server) ListReports(ctx context.Context, req *pb.ListReportsRequest) (*pb.ReportList, error) {
+	logger.InfoContext(ctx, "list reports called")
+	caller, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
 	}
-	userIDs := md.Get("user_id")
-	if len(userIDs) == 0 {
-		return nil, status.Error(codes.Unauthenticated, "no user_id")
+
+	reports, err := s.service.ListReports(ctx, req, caller)
+	if err != nil {
+		return nil, toStatusError(err)
 	}
+	return reports, nil
+}
 
-	userId, _ := strconv.Atoi(userIDs[0])
+func (s *Server) UpdateReportStatus(ctx context.Context, req *pb.UpdateReportStatusRequest) (*pb.Report, error) {
+	logger.InfoContext(ctx, "update report status called")
+	caller, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.service.UpdateReportStatus(ctx, req, caller)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return report, nil
+}
+
+func (s *Server) DeleteUserContent(ctx context.Context, req *pb.DeleteUserContentRequest) (*emptypb.Empty, error) {
+	logger.InfoContext(ctx, "delete user content called")
+	caller, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.service.DeleteUserContent(ctx, req, caller)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
 
-	return s.service.GetAllPosts(pagination, int32(userId))
+func (s *Server) ExportUserContent(ctx context.Context, _ *emptypb.Empty) (*pb.UserContent, error) {
+	logger.InfoContext(ctx, "export user content called")
+	caller, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := s.service.ExportUserContent(ctx, caller)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return content, nil
+}
+
+func (s *Server) GetUserStats(ctx context.Context, req *pb.UserStatsRequest) (*pb.UserStats, error) {
+	logger.InfoContext(ctx, "get user stats called")
+	_, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.service.GetUserStats(ctx, req)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return stats, nil
+}
+
+// callerFromContext returns the caller authenticated by auth.UnaryServerInterceptor.
+func callerFromContext(ctx context.Context) (auth.Caller, error) {
+	caller, ok := auth.FromContext(ctx)
+	if !ok {
+		return auth.Caller{}, status.Error(codes.Unauthenticated, "unauthenticated")
+	}
+	return caller, nil
 }
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"social-network/pkg/validation"
+	customerror "social-network/posts-comments-service/internal/errors"
+)
+
+func toStatusError(err error) error {
+	var notFoundErr *customerror.NotFoundError
+	var invalidArgErr *customerror.InvalidArgumentError
+	var validationErr *customerror.ValidationError
+	var permissionDeniedErr *customerror.PermissionDeniedError
+	var alreadyExistsErr *customerror.AlreadyExistsError
+
+	switch {
+	case errors.As(err, &validationErr):
+		return validation.StatusError(validationErr)
+	case errors.As(err, &notFoundErr):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.As(err, &invalidArgErr):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.As(err, &permissionDeniedErr):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.As(err, &alreadyExistsErr):
+		return status.Error(codes.AlreadyExists, err.Error())
+	}
+	return err
+}
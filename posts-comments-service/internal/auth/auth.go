@@ -0,0 +1,27 @@
+package auth
+
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+	// RoleService is used by other services calling on their own behalf
+	RoleService = "service"
+)
+
+// Caller is the user on whose behalf the api-gateway calls the service.
+type Caller struct {
+	UserId int32
+	Role   string
+}
+
+func (c Caller) IsModerator() bool {
+	return c.Role == RoleModerator || c.Role == RoleAdmin
+}
+
+func (c Caller) Owns(creatorId int32) bool {
+	return c.UserId == creatorId
+}
+
+func (c Caller) IsService() bool {
+	return c.Role == RoleService
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"social-network/pkg/internaltoken"
+	"social-network/pkg/logger"
+	"social-network/posts-comments-service/internal/config"
+	"strings"
+)
+
+// Audience is the audience of the internal tokens posts-comments-service accepts.
+const Audience = "posts-service"
+
+// healthService is called by probes, which hold no token.
+const healthService = "/grpc.health.v1.Health/"
+
+var knownRoles = map[string]bool{
+	RoleUser:      true,
+	RoleModerator: true,
+	RoleAdmin:     true,
+	RoleService:   true,
+}
+
+type ctxKey struct{}
+
+func NewContext(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, ctxKey{}, caller)
+}
+
+// FromContext returns the caller the interceptors authenticated.
+func FromContext(ctx context.Context) (Caller, bool) {
+	caller, ok := ctx.Value(ctxKey{}).(Caller)
+	return caller, ok
+}
+
+func NewVerifier(cfg *config.Config) (*internaltoken.Verifier, error) {
+	return internaltoken.NewVerifier(cfg.InternalTokenKey, Audience)
+}
+
+// UnaryServerInterceptor authenticates every call by the internal token the
+// api-gateway or user-service signed for it. The user_id and role sent as
+// plain metadata are never trusted.
+func UnaryServerInterceptor(verifier *internaltoken.Verifier) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, verifier, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor authenticates streams like UnaryServerInterceptor does calls.
+func StreamServerInterceptor(verifier *internaltoken.Verifier) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), verifier, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func authenticate(ctx context.Context, verifier *internaltoken.Verifier, method string) (context.Context, error) {
+	if strings.HasPrefix(method, healthService) {
+		return ctx, nil
+	}
+
+	principal, err := verifier.VerifyIncoming(ctx)
+	if err != nil {
+		logger.WarnContext(ctx, "unauthenticated call", "method", method, "error", err)
+		return nil, status.Error(codes.Unauthenticated, "invalid internal token")
+	}
+	if !knownRoles[principal.Role] {
+		logger.WarnContext(ctx, "unknown role in internal token", "method", method, "role", principal.Role)
+		return nil, status.Error(codes.Unauthenticated, "invalid internal token")
+	}
+
+	return NewContext(ctx, Caller{UserId: principal.UserId, Role: principal.Role}), nil
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
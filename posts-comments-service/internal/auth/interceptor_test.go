@@ -0,0 +1,246 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"social-network/pkg/internaltoken"
+)
+
+const (
+	testKey       = "test-key"
+	tokenKey      = "x-internal-token"
+	getPostMethod = "/PostsService/GetPostById"
+	healthMethod  = "/grpc.health.v1.Health/Check"
+)
+
+func newVerifier(t *testing.T) *internaltoken.Verifier {
+	t.Helper()
+	verifier, err := internaltoken.NewVerifier(testKey, Audience)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return verifier
+}
+
+func sign(t *testing.T, key string, audience string, principal internaltoken.Principal) string {
+	t.Helper()
+	signer, err := internaltoken.NewSigner(key, "api-gateway", audience)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := signer.Sign(principal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func signClaims(t *testing.T, method jwt.SigningMethod, key any, c jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(method, c).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+type authCase struct {
+	name       string
+	method     string
+	md         metadata.MD
+	want       Caller
+	wantCaller bool
+	wantCode   codes.Code
+}
+
+func authCases(t *testing.T) []authCase {
+	user := sign(t, testKey, Audience, internaltoken.Principal{UserId: 7, Role: RoleUser})
+	admin := sign(t, testKey, Audience, internaltoken.Principal{UserId: 1, Role: RoleAdmin})
+	hourAgo := time.Now().Add(-time.Hour)
+
+	return []authCase{
+		{
+			name:       "user token",
+			method:     getPostMethod,
+			md:         metadata.Pairs(tokenKey, user),
+			want:       Caller{UserId: 7, Role: RoleUser},
+			wantCaller: true,
+		},
+		{
+			name:       "service token",
+			method:     getPostMethod,
+			md:         metadata.Pairs(tokenKey, sign(t, testKey, Audience, internaltoken.Principal{Role: RoleService})),
+			want:       Caller{Role: RoleService},
+			wantCaller: true,
+		},
+		{
+			name:       "plain metadata next to the token does not override it",
+			method:     getPostMethod,
+			md:         metadata.Pairs(tokenKey, user, "user_id", "1", "role", RoleAdmin),
+			want:       Caller{UserId: 7, Role: RoleUser},
+			wantCaller: true,
+		},
+		{
+			name:     "bare user_id and role without a token",
+			method:   getPostMethod,
+			md:       metadata.Pairs("user_id", "1", "role", RoleAdmin),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "no metadata",
+			method:   getPostMethod,
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "wrong key",
+			method:   getPostMethod,
+			md:       metadata.Pairs(tokenKey, sign(t, "other-key", Audience, internaltoken.Principal{UserId: 1, Role: RoleAdmin})),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "wrong audience",
+			method:   getPostMethod,
+			md:       metadata.Pairs(tokenKey, sign(t, testKey, "user-service", internaltoken.Principal{UserId: 1, Role: RoleAdmin})),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:   "expired",
+			method: getPostMethod,
+			md: metadata.Pairs(tokenKey, signClaims(t, jwt.SigningMethodHS256, []byte(testKey), jwt.MapClaims{
+				"sub": "1", "aud": Audience, "role": RoleAdmin, "iat": hourAgo.Unix(), "exp": hourAgo.Add(time.Minute).Unix(),
+			})),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:   "missing exp",
+			method: getPostMethod,
+			md: metadata.Pairs(tokenKey, signClaims(t, jwt.SigningMethodHS256, []byte(testKey), jwt.MapClaims{
+				"sub": "1", "aud": Audience, "role": RoleAdmin, "iat": time.Now().Unix(),
+			})),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:   "alg none",
+			method: getPostMethod,
+			md: metadata.Pairs(tokenKey, signClaims(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, jwt.MapClaims{
+				"sub": "1", "aud": Audience, "role": RoleAdmin, "iat": time.Now().Unix(), "exp": time.Now().Add(time.Minute).Unix(),
+			})),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "two tokens",
+			method:   getPostMethod,
+			md:       metadata.Pairs(tokenKey, user, tokenKey, admin),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "unknown role",
+			method:   getPostMethod,
+			md:       metadata.Pairs(tokenKey, sign(t, testKey, Audience, internaltoken.Principal{UserId: 1, Role: "superuser"})),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:   "health check without a token",
+			method: healthMethod,
+		},
+		{
+			name:   "health check ignores plain metadata",
+			method: healthMethod,
+			md:     metadata.Pairs("user_id", "1", "role", RoleAdmin),
+		},
+		{
+			name:     "method named like the health service",
+			method:   "/grpc.health.v1.HealthCheck/Check",
+			wantCode: codes.Unauthenticated,
+		},
+	}
+}
+
+func incomingContext(md metadata.MD) context.Context {
+	if md == nil {
+		return context.Background()
+	}
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+func checkAuth(t *testing.T, tc authCase, called bool, ctx context.Context, err error) {
+	t.Helper()
+	if tc.wantCode != codes.OK {
+		if status.Code(err) != tc.wantCode {
+			t.Fatalf("error = %v, want code %v", err, tc.wantCode)
+		}
+		if called {
+			t.Fatal("handler was called")
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("error = %v", err)
+	}
+	if !called {
+		t.Fatal("handler was not called")
+	}
+
+	got, ok := FromContext(ctx)
+	if ok != tc.wantCaller {
+		t.Fatalf("caller in context = %v, want %v", ok, tc.wantCaller)
+	}
+	if got != tc.want {
+		t.Errorf("caller = %+v, want %+v", got, tc.want)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor(newVerifier(t))
+
+	for _, tc := range authCases(t) {
+		t.Run(tc.name, func(t *testing.T) {
+			called := false
+			var handlerCtx context.Context
+			handler := func(ctx context.Context, req any) (any, error) {
+				called = true
+				handlerCtx = ctx
+				return nil, nil
+			}
+
+			_, err := interceptor(incomingContext(tc.md), nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, handler)
+			checkAuth(t, tc, called, handlerCtx, err)
+		})
+	}
+}
+
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	interceptor := StreamServerInterceptor(newVerifier(t))
+
+	for _, tc := range authCases(t) {
+		t.Run(tc.name, func(t *testing.T) {
+			called := false
+			var handlerCtx context.Context
+			handler := func(srv any, stream grpc.ServerStream) error {
+				called = true
+				handlerCtx = stream.Context()
+				return nil
+			}
+
+			stream := &fakeStream{ctx: incomingContext(tc.md)}
+			err := interceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: tc.method}, handler)
+			checkAuth(t, tc, called, handlerCtx, err)
+		})
+	}
+}
@@ -1,19 +1,66 @@
 package config
 
+import (
+	"social-network/pkg/certs"
+	"social-network/pkg/env"
+	"social-network/pkg/postgres"
+	"time"
+)
+
 type Config struct {
-	ServAddr         string
-	PostgresDb       string
-	PostgresUser     string
-	PostgresPassword string
-	PostgresPort     int
+	ServAddr string
+	// ServHost is the host the grpc server listens on
+	ServHost string
+
+	Postgres postgres.Config
+
+	// InternalTLS is the certificate of the service and the CA its peers are
+	// verified with, calls between the services are mutual TLS once it is set
+	InternalTLS certs.Config
+	// InternalTokenKey verifies the tokens the api-gateway and user-service
+	// sign for the users they call on behalf of
+	InternalTokenKey string
+
+	// ReportHideThreshold is how many open reports hide a post until a moderator reviews it
+	ReportHideThreshold int
+
+	HealthCheckInterval time.Duration
+
+	// MetricsAddr is where the Prometheus metrics are served over HTTP
+	MetricsAddr string
 }
 
 func NewConfig() *Config {
 	return &Config{
-		ServAddr:         ":50051",
-		PostgresUser:     "user",
-		PostgresPassword: "password",
-		PostgresPort:     5432,
-		PostgresDb:       "posts-db",
+		ServAddr: ":50051",
+		ServHost: "posts-service",
+
+		Postgres: postgres.Config{
+			Host:     "posts-postgres",
+			Port:     5432,
+			Db:       "posts-db",
+			User:     "user",
+			Password: "password",
+
+			ConnectAttempts: env.Int("DB_CONNECT_ATTEMPTS", 8),
+			ConnectBackoff:  500 * time.Millisecond,
+			MaxBackoff:      5 * time.Second,
+
+			TraceQueries: true,
+		},
+
+		InternalTLS:      certs.NewConfig("INTERNAL_TLS"),
+		InternalTokenKey: env.Secret("INTERNAL_TOKEN_KEY"),
+
+		ReportHideThreshold: env.Int("REPORT_HIDE_THRESHOLD", 5),
+
+		HealthCheckInterval: 5 * time.Second,
+
+		MetricsAddr: ":9090",
 	}
 }
+
+// NewInternalTLS supplies the config of the certificates of the service.
+func NewInternalTLS(cfg *Config) certs.Config {
+	return cfg.InternalTLS
+}
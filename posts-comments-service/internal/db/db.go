@@ -1,35 +1,36 @@
 package db
 
 import (
-	"context"
-	"database/sql"
-	"fmt"
-	_ "github.com/jackc/pgx/v5/stdlib"
-	"github.com/uptrace/bun"
-	"github.com/uptrace/bun/dialect/pgdialect"
+	"go.uber.org/fx"
+	"social-network/pkg/postgres"
 	"social-network/posts-comments-service/internal/config"
-	"social-network/posts-comments-service/internal/logger"
 	"social-network/posts-comments-service/internal/repository"
 )
 
-func InitDb(cfg *config.Config) *bun.DB {
-	dsn := fmt.Sprintf("postgres://%s:%s@posts-postgres:%d/%s?sslmode=disable",
-		cfg.PostgresUser, cfg.PostgresPassword, cfg.PostgresPort, cfg.PostgresDb)
+// Module provides the *bun.DB of posts-comments-service with its schema applied.
+var Module = fx.Module("db",
+	postgres.Module,
+	fx.Provide(newConfig, newSchema),
+)
 
-	sqldb, err := sql.Open("pgx", dsn)
-	if err != nil {
-		logger.Error(fmt.Sprintf("init db err: %s", err.Error()))
-	}
+var models = []any{
+	(*repository.Post)(nil),
+	(*repository.AuditEntry)(nil),
+	(*repository.Report)(nil),
+}
 
-	db := bun.NewDB(sqldb, pgdialect.New())
-	_, err = db.NewCreateTable().
-		IfNotExists().
-		Model((*repository.Post)(nil)).
-		Exec(context.Background())
+// migrations add columns and indexes introduced after a table was first created,
+// CREATE TABLE IF NOT EXISTS leaves existing tables untouched
+var migrations = []string{
+	`ALTER TABLE posts ADD COLUMN IF NOT EXISTS is_hidden BOOLEAN NOT NULL DEFAULT FALSE`,
+	`ALTER TABLE posts ADD COLUMN IF NOT EXISTS hidden_reason VARCHAR`,
+	`CREATE INDEX IF NOT EXISTS posts_creator_id_idx ON posts (creator_id)`,
+}
+
+func newConfig(cfg *config.Config) postgres.Config {
+	return cfg.Postgres
+}
 
-	if err != nil {
-		logger.Error(fmt.Sprintf("create table err: %s", err.Error()))
-	}
-	logger.Info(fmt.Sprintf("init db success: %s", dsn))
-	return db
+func newSchema() postgres.Schema {
+	return postgres.Schema{Models: models, Migrations: migrations}
 }
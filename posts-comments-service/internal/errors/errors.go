@@ -1,7 +1,35 @@
 package customerror
 
+import "social-network/pkg/validation"
+
 type NotFoundError struct{}
 
 func (nfe NotFoundError) Error() string {
 	return "Ресурс не найден"
 }
+
+type InvalidArgumentError struct {
+	Message string
+}
+
+func (iae InvalidArgumentError) Error() string {
+	return iae.Message
+}
+
+type FieldError = validation.FieldError
+
+type ValidationError = validation.ValidationError
+
+type PermissionDeniedError struct{}
+
+func (pde PermissionDeniedError) Error() string {
+	return "Недостаточно прав"
+}
+
+type AlreadyExistsError struct {
+	Message string
+}
+
+func (aee AlreadyExistsError) Error() string {
+	return aee.Message
+}
@@ -0,0 +1,93 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"github.com/uptrace/bun"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"social-network/pkg/logger"
+	"social-network/posts-comments-service/internal/config"
+	"time"
+)
+
+// ServiceName is the name PostsService is registered under in the health service.
+const ServiceName = "PostsService"
+
+func NewServer() *health.Server {
+	return health.NewServer()
+}
+
+// InvokeHealthChecks keeps the serving status in the health service in step
+// with the database: posts-comments-service can serve nothing without it.
+func InvokeHealthChecks(lc fx.Lifecycle, cfg *config.Config, db *bun.DB, healthServer *health.Server) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(cfg.HealthCheckInterval)
+				defer ticker.Stop()
+
+				for {
+					setStatus(healthServer, ping(ctx, db, cfg.HealthCheckInterval))
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			cancel()
+			<-done
+			healthServer.Shutdown()
+			return nil
+		},
+	})
+}
+
+func ping(ctx context.Context, db *bun.DB, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return db.PingContext(ctx)
+}
+
+func setStatus(healthServer *health.Server, err error) {
+	status := healthpb.HealthCheckResponse_SERVING
+	if err != nil {
+		logger.Error("database ping failed", "error", err)
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	healthServer.SetServingStatus("", status)
+	healthServer.SetServingStatus(ServiceName, status)
+}
+
+// Probe asks the running service whether it is serving, it backs the
+// healthcheck command used by docker-compose.
+func Probe(addr string, creds credentials.TransportCredentials, timeout time.Duration) error {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: ServiceName})
+	if err != nil {
+		return err
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("service is %s", resp.GetStatus())
+	}
+	return nil
+}
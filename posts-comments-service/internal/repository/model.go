@@ -9,12 +9,42 @@ import (
 type Post struct {
 	bun.BaseModel `bun:"table:posts,select:posts"`
 
-	Id          int32     `bun:"id,pk,autoincrement" json:"id"`
-	Name        string    `bun:"name" json:"name"`
-	Description string    `bun:"description" json:"description"`
-	CreatorId   int32     `bun:"creator_id" json:"creator_id"`
-	IsPrivate   bool      `bun:"is_private" json:"is_private"`
-	CreatedAt   time.Time `bun:"created_at" json:"created_at"`
-	UpdatedAt   time.Time `bun:"updated_at" json:"updated_at"`
-	Tags        []string  `bun:"tags" json:"tags"`
+	Id           int32     `bun:"id,pk,autoincrement" json:"id"`
+	Name         string    `bun:"name" json:"name" validate:"required,max=120"`
+	Description  string    `bun:"description" json:"description" validate:"max=5000"`
+	CreatorId    int32     `bun:"creator_id" json:"creator_id"`
+	IsPrivate    bool      `bun:"is_private" json:"is_private"`
+	IsHidden     bool      `bun:"is_hidden,notnull" json:"is_hidden"`
+	HiddenReason string    `bun:"hidden_reason" json:"hidden_reason"`
+	CreatedAt    time.Time `bun:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `bun:"updated_at" json:"updated_at"`
+	Tags         []string  `bun:"tags" json:"tags" validate:"max=10,dive,min=1,max=32,hashtag"`
+}
+
+type AuditEntry struct {
+	bun.BaseModel `bun:"table:audit_log,select:audit_log"`
+
+	Id        int64     `bun:"id,pk,autoincrement"`
+	ActorId   int32     `bun:"actor_id"`
+	ActorRole string    `bun:"actor_role"`
+	Action    string    `bun:"action"`
+	TargetId  int32     `bun:"target_id"`
+	Details   string    `bun:"details"`
+	CreatedAt time.Time `bun:"created_at"`
+}
+
+// Report is a user's complaint about a post. Reason and status hold the
+// lowercase names of the proto enums without their prefix.
+type Report struct {
+	bun.BaseModel `bun:"table:reports,select:reports"`
+
+	Id         int32     `bun:"id,pk,autoincrement" json:"id"`
+	PostId     int32     `bun:"post_id,notnull,unique:reports_post_reporter" json:"post_id"`
+	ReporterId int32     `bun:"reporter_id,notnull,unique:reports_post_reporter" json:"reporter_id"`
+	Reason     string    `bun:"reason,notnull" json:"reason" validate:"required"`
+	Comment    string    `bun:"comment" json:"comment" validate:"max=1000"`
+	Status     string    `bun:"status,notnull" json:"status"`
+	ReviewerId int32     `bun:"reviewer_id" json:"reviewer_id"`
+	CreatedAt  time.Time `bun:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `bun:"updated_at" json:"updated_at"`
 }
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"social-network/pkg/logger"
+	customerror "social-network/posts-comments-service/internal/errors"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// AddReport returns AlreadyExistsError when the user has already reported the post.
+func (pr *PostRepository) AddReport(ctx context.Context, report Report) error {
+	now := time.Now()
+	report.CreatedAt = now
+	report.UpdatedAt = now
+
+	res, err := pr.db.NewInsert().
+		Model(&report).
+		On("CONFLICT (post_id, reporter_id) DO NOTHING").
+		Exec(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "error adding report", "error", err)
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return &customerror.AlreadyExistsError{Message: "post is already reported"}
+	}
+	return nil
+}
+
+// CountReports counts reports on the post in any of the statuses.
+func (pr *PostRepository) CountReports(ctx context.Context, postId int32, statuses []string) (int, error) {
+	count, err := pr.db.NewSelect().
+		Model((*Report)(nil)).
+		Where("post_id = ?", postId).
+		Where("status IN (?)", bun.In(statuses)).
+		Count(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "error counting reports", "error", err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// ListReports returns the oldest reports first, an empty status matches every report.
+func (pr *PostRepository) ListReports(ctx context.Context, status string, limit int32, offset int32) ([]Report, int, error) {
+	var reports []Report
+	query := pr.db.NewSelect().
+		Model(&reports).
+		Order("created_at ASC", "id ASC").
+		Limit(int(limit)).
+		Offset(int(offset))
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	total, err := query.ScanAndCount(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "error listing reports", "error", err)
+		return nil, 0, err
+	}
+
+	return reports, total, nil
+}
+
+func (pr *PostRepository) GetReportById(ctx context.Context, id int32) (Report, error) {
+	var report Report
+	err := pr.db.NewSelect().
+		Model(&report).
+		Where("id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.InfoContext(ctx, "not found")
+			return Report{}, &customerror.NotFoundError{}
+		}
+		logger.ErrorContext(ctx, "error getting report", "error", err)
+		return Report{}, err
+	}
+
+	return report, nil
+}
+
+func (pr *PostRepository) UpdateReport(ctx context.Context, report Report) error {
+	report.UpdatedAt = time.Now()
+	_, err := pr.db.NewUpdate().
+		Model(&report).
+		Column("status", "reviewer_id", "updated_at").
+		Where("id = ?", report.Id).
+		Exec(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "error updating report", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (pr *PostRepository) GetReportsByReporter(ctx context.Context, userId int32) ([]Report, error) {
+	var reports []Report
+	err := pr.db.NewSelect().
+		Model(&reports).
+		Where("reporter_id = ?", userId).
+		Order("created_at ASC").
+		Scan(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "error getting user reports", "error", err)
+		return nil, err
+	}
+
+	return reports, nil
+}
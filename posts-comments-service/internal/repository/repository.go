@@ -4,9 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"fmt"
+	"social-network/pkg/logger"
 	customerror "social-network/posts-comments-service/internal/errors"
-	"social-network/posts-comments-service/internal/logger"
+	"time"
 
 	"github.com/uptrace/bun"
 )
@@ -19,96 +19,201 @@ func NewPostRepository(db *bun.DB) *PostRepository {
 	return &PostRepository{db}
 }
 
-func (pr *PostRepository) AddPost(post Post) error {
+func (pr *PostRepository) AddPost(ctx context.Context, post Post) error {
 	_, err := pr.db.NewInsert().
 		Model(&post).
-		Exec(context.Background())
+		Exec(ctx)
 	if err != nil {
-		logger.Error(fmt.Sprintf("error adding post: %v", err))
+		logger.ErrorContext(ctx, "error adding post", "error", err)
 		return err
 	}
 
 	return nil
 }
 
-func (pr *PostRepository) DeletePost(id int32) error {
-	_, err := pr.GetPostById(id)
+func (pr *PostRepository) DeletePost(ctx context.Context, id int32) error {
+	_, err := pr.GetPostById(ctx, id)
 	if err != nil {
 		if errors.Is(err, &customerror.NotFoundError{}) {
 			return &customerror.NotFoundError{}
 		}
 	}
 
-	_, err = pr.db.NewDelete().
-		Model((*Post)(nil)).
-		Where("id = ?", id).
-		Exec(context.Background())
+	err = pr.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewDelete().
+			Model((*Report)(nil)).
+			Where("post_id = ?", id).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewDelete().
+			Model((*Post)(nil)).
+			Where("id = ?", id).
+			Exec(ctx)
+		return err
+	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			logger.Info("not fount")
+			logger.InfoContext(ctx, "not fount")
 			return &customerror.NotFoundError{}
 		}
-		logger.Error(fmt.Sprintf("error deleting post: %v", err))
+		logger.ErrorContext(ctx, "error deleting post", "error", err)
 		return err
 	}
 
 	return nil
 }
 
-func (pr *PostRepository) UpdatePost(post Post) error {
-	_, err := pr.GetPostById(post.Id)
+func (pr *PostRepository) UpdatePost(ctx context.Context, post Post, columns []string) error {
+	_, err := pr.GetPostById(ctx, post.Id)
 	if err != nil {
-		if errors.Is(err, &customerror.NotFoundError{}) {
-			logger.Info("not found")
-			return &customerror.NotFoundError{}
-		}
+		return err
 	}
 
 	_, err = pr.db.NewUpdate().
 		Model(&post).
+		Column(columns...).
 		Where("id = ?", post.Id).
-		OmitZero().
-		Exec(context.Background())
+		Exec(ctx)
 	if err != nil {
-		logger.Error(fmt.Sprintf("error updating post: %v", err))
+		logger.ErrorContext(ctx, "error updating post", "error", err)
 		return err
 	}
 
 	return nil
 }
 
-func (pr *PostRepository) GetPostById(id int32) (Post, error) {
+func (pr *PostRepository) GetPostById(ctx context.Context, id int32) (Post, error) {
 	var post Post
 	err := pr.db.NewSelect().
 		Model(&post).
 		Where("id = ?", id).
-		Scan(context.Background())
+		Scan(ctx)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			logger.Info("not found")
+			logger.InfoContext(ctx, "not found")
 			return Post{}, &customerror.NotFoundError{}
 		}
-		logger.Error(fmt.Sprintf("error getting post: %v", err))
+		logger.ErrorContext(ctx, "error getting post", "error", err)
 		return Post{}, err
 	}
 
 	return post, nil
 }
 
-func (pr *PostRepository) GetAllPosts(limit int32, offset int32, userId int32) ([]Post, error) {
+func (pr *PostRepository) GetAllPosts(ctx context.Context, limit int32, offset int32, userId int32, excludeUserIds []int32) ([]Post, error) {
 	var posts []Post
-	err := pr.db.NewSelect().
+	query := pr.db.NewSelect().
 		Model(&posts).
-		Where("is_private = ?", false).
-		WhereOr("creator_id = ?", int(userId)).
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("is_private = ?", false).
+				WhereOr("creator_id = ?", int(userId))
+		}).
+		Where("is_hidden = ?", false).
 		Order("created_at DESC").
 		Limit(int(limit)).
-		Offset(int(offset)).
-		Scan(context.Background())
+		Offset(int(offset))
+	if len(excludeUserIds) > 0 {
+		query = query.Where("creator_id NOT IN (?)", bun.In(excludeUserIds))
+	}
+
+	err := query.Scan(ctx)
 	if err != nil {
-		logger.Error(fmt.Sprintf("error getting all posts: %v", err))
+		logger.ErrorContext(ctx, "error getting all posts", "error", err)
 		return nil, err
 	}
 
 	return posts, nil
 }
+
+func (pr *PostRepository) SetHidden(ctx context.Context, id int32, hidden bool, reason string) error {
+	_, err := pr.db.NewUpdate().
+		Model((*Post)(nil)).
+		Set("is_hidden = ?", hidden).
+		Set("hidden_reason = ?", reason).
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "error hiding post", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (pr *PostRepository) WriteAudit(ctx context.Context, entry AuditEntry) error {
+	entry.CreatedAt = time.Now()
+	_, err := pr.db.NewInsert().
+		Model(&entry).
+		Exec(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "error writing audit log", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteUserContent removes the user's posts with every report on them and the reports the user filed.
+func (pr *PostRepository) DeleteUserContent(ctx context.Context, userId int32) error {
+	err := pr.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		userPosts := tx.NewSelect().
+			Model((*Post)(nil)).
+			Column("id").
+			Where("creator_id = ?", userId)
+
+		_, err := tx.NewDelete().
+			Model((*Report)(nil)).
+			Where("reporter_id = ?", userId).
+			WhereOr("post_id IN (?)", userPosts).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewDelete().
+			Model((*Post)(nil)).
+			Where("creator_id = ?", userId).
+			Exec(ctx)
+		return err
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "error deleting user content", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (pr *PostRepository) GetPostsByCreator(ctx context.Context, userId int32) ([]Post, error) {
+	var posts []Post
+	err := pr.db.NewSelect().
+		Model(&posts).
+		Where("creator_id = ?", userId).
+		Order("created_at ASC").
+		Scan(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "error getting user posts", "error", err)
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+func (pr *PostRepository) CountPublicPosts(ctx context.Context, userId int32) (int, error) {
+	count, err := pr.db.NewSelect().
+		Model((*Post)(nil)).
+		Where("creator_id = ?", userId).
+		Where("is_private = ?", false).
+		Where("is_hidden = ?", false).
+		Count(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "error counting user posts", "error", err)
+		return 0, err
+	}
+
+	return count, nil
+}
@@ -1,39 +1,50 @@
 package server
 
 import (
-	"context"
-	"fmt"
 	"go.uber.org/fx"
 	"google.golang.org/grpc"
-	"net"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"net/http"
+	"social-network/pkg/certs"
+	"social-network/pkg/internaltoken"
+	"social-network/pkg/metrics"
+	"social-network/pkg/serving"
 	"social-network/posts-comments-service/internal/app"
+	"social-network/posts-comments-service/internal/auth"
 	"social-network/posts-comments-service/internal/config"
-	"social-network/posts-comments-service/internal/logger"
 	pb "social-network/protos"
+	"time"
 )
 
-func RunServer(lc fx.Lifecycle, cfg *config.Config, server *app.Server) error {
-	grpcServer := grpc.NewServer()
-	lc.Append(fx.Hook{
-		OnStart: func(_ context.Context) error {
-			go func() {
-				lis, err := net.Listen("tcp", "posts-service"+cfg.ServAddr)
-				if err != nil {
-					logger.Error(fmt.Sprintf("failed to listen: %v", err))
-				}
-				logger.Info("starting grpc server on " + cfg.ServAddr)
-				pb.RegisterPostsServiceServer(grpcServer, server)
+// RunServer serves the gRPC API with the health service next to it.
+func RunServer(
+	lc fx.Lifecycle,
+	cfg *config.Config,
+	server *app.Server,
+	healthServer *health.Server,
+	internal *certs.Reloader,
+	verifier *internaltoken.Verifier,
+) {
+	grpcServer := serving.NewGRPCServer(
+		grpc.Creds(certs.ServerCredentials(internal)),
+		grpc.ChainUnaryInterceptor(auth.UnaryServerInterceptor(verifier)),
+		grpc.ChainStreamInterceptor(auth.StreamServerInterceptor(verifier)),
+	)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	pb.RegisterPostsServiceServer(grpcServer, server)
+	serving.GRPC(lc, cfg.ServHost+cfg.ServAddr, grpcServer)
+}
 
-				if err = grpcServer.Serve(lis); err != nil {
-					logger.Error("error starting server: " + err.Error())
-				}
-			}()
-			return nil
-		},
-		OnStop: func(ctx context.Context) error {
-			grpcServer.GracefulStop()
-			return nil
-		},
+// RunMetricsServer serves /metrics on its own port, posts-comments-service has no
+// HTTP server otherwise. Prometheus scrapes it with a certificate of its own.
+func RunMetricsServer(lc fx.Lifecycle, cfg *config.Config, internal *certs.Reloader) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	serving.HTTP(lc, &http.Server{
+		Addr:              cfg.MetricsAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+		TLSConfig:         certs.ServerTLS(internal),
 	})
-	return nil
 }
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// postsCreated counts the posts stored by AddPost.
+	postsCreated = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "posts_created_total",
+		Help: "Posts created.",
+	})
+
+	// postsReported counts the reports filed against posts by reason.
+	postsReported = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "posts_reported_total",
+		Help: "Reports filed against posts by reason.",
+	}, []string{"reason"})
+
+	// postsAutoHidden counts the posts hidden after collecting too many reports.
+	postsAutoHidden = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "posts_auto_hidden_total",
+		Help: "Posts hidden automatically once they reached the report threshold.",
+	})
+)
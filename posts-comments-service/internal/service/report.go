@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"slices"
+	"social-network/pkg/validation"
+	"social-network/posts-comments-service/internal/auth"
+	customerror "social-network/posts-comments-service/internal/errors"
+	"social-network/posts-comments-service/internal/repository"
+	pb "social-network/protos"
+	"strings"
+)
+
+const (
+	reportStatusOpen      = "open"
+	reportStatusReviewing = "reviewing"
+	reportStatusActioned  = "actioned"
+	reportStatusDismissed = "dismissed"
+
+	// autoHiddenReason marks posts hidden by the report threshold, dismissing
+	// their reports may bring them back. A post hidden for an actioned report stays hidden.
+	autoHiddenReason     = "reports"
+	actionedHiddenReason = "report actioned"
+
+	defaultReportsPageSize = 20
+	maxReportsPageSize     = 100
+)
+
+// activeReportStatuses are the statuses still awaiting a moderator's decision.
+var activeReportStatuses = []string{reportStatusOpen, reportStatusReviewing}
+
+var reportTransitions = map[string][]string{
+	reportStatusOpen:      {reportStatusReviewing, reportStatusActioned, reportStatusDismissed},
+	reportStatusReviewing: {reportStatusOpen, reportStatusActioned, reportStatusDismissed},
+}
+
+// systemCaller is recorded in the audit log for actions nobody requested directly.
+var systemCaller = auth.Caller{Role: "system"}
+
+func (ps *PostService) ReportPost(ctx context.Context, req *pb.ReportPostRequest, caller auth.Caller) error {
+	report := repository.Report{
+		PostId:     req.GetPostId(),
+		ReporterId: caller.UserId,
+		Reason:     enumName(pb.ReportReason_name, int32(req.GetReason()), "REPORT_REASON_"),
+		Comment:    req.GetComment(),
+		Status:     reportStatusOpen,
+	}
+	err := validation.Struct(report)
+	if err != nil {
+		return err
+	}
+
+	post, err := ps.repository.GetPostById(ctx, req.GetPostId())
+	if err != nil {
+		return err
+	}
+	if post.IsHidden && !caller.Owns(post.CreatorId) && !caller.IsModerator() {
+		return &customerror.NotFoundError{}
+	}
+	if caller.Owns(post.CreatorId) {
+		return &customerror.InvalidArgumentError{Message: "cannot report own post"}
+	}
+
+	err = ps.repository.AddReport(ctx, report)
+	if err != nil {
+		return err
+	}
+	postsReported.WithLabelValues(report.Reason).Inc()
+
+	if post.IsHidden || ps.reportHideThreshold <= 0 {
+		return nil
+	}
+	count, err := ps.repository.CountReports(ctx, post.Id, activeReportStatuses)
+	if err != nil {
+		return err
+	}
+	if count >= ps.reportHideThreshold {
+		err = ps.repository.SetHidden(ctx, post.Id, true, autoHiddenReason)
+		if err != nil {
+			return err
+		}
+		postsAutoHidden.Inc()
+		ps.audit(ctx, systemCaller, "post.auto_hide", post.Id, fmt.Sprintf("%d reports", count))
+	}
+	return nil
+}
+
+func (ps *PostService) ListReports(ctx context.Context, req *pb.ListReportsRequest, caller auth.Caller) (*pb.ReportList, error) {
+	if !caller.IsModerator() {
+		return nil, &customerror.PermissionDeniedError{}
+	}
+
+	status := ""
+	if req.GetStatus() != pb.ReportStatus_REPORT_STATUS_UNSPECIFIED {
+		status = enumName(pb.ReportStatus_name, int32(req.GetStatus()), "REPORT_STATUS_")
+		if status == "" {
+			return nil, &customerror.InvalidArgumentError{Message: "unknown report status"}
+		}
+	}
+
+	pageSize := req.GetPagination().GetPageSize()
+	if pageSize <= 0 {
+		pageSize = defaultReportsPageSize
+	}
+	pageSize = min(pageSize, maxReportsPageSize)
+
+	reports, total, err := ps.repository.ListReports(ctx, status, pageSize, req.GetPagination().GetPageIndex())
+	if err != nil {
+		return nil, err
+	}
+
+	list := &pb.ReportList{
+		Reports: make([]*pb.Report, 0, len(reports)),
+		Total:   int32(total),
+	}
+	for _, report := range reports {
+		list.Reports = append(list.Reports, toPbReport(report))
+	}
+	return list, nil
+}
+
+// UpdateReportStatus moves a report through its review. Actioning a report
+// hides the post, dismissing the last reports brings back a post the
+// threshold hid.
+func (ps *PostService) UpdateReportStatus(ctx context.Context, req *pb.UpdateReportStatusRequest, caller auth.Caller) (*pb.Report, error) {
+	if !caller.IsModerator() {
+		return nil, &customerror.PermissionDeniedError{}
+	}
+
+	status := enumName(pb.ReportStatus_name, int32(req.GetStatus()), "REPORT_STATUS_")
+	if status == "" {
+		return nil, &customerror.InvalidArgumentError{Message: "unknown report status"}
+	}
+
+	report, err := ps.repository.GetReportById(ctx, req.GetReportId())
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(reportTransitions[report.Status], status) {
+		return nil, &customerror.InvalidArgumentError{
+			Message: fmt.Sprintf("report cannot move from %s to %s", report.Status, status),
+		}
+	}
+
+	report.Status = status
+	report.ReviewerId = caller.UserId
+	err = ps.repository.UpdateReport(ctx, report)
+	if err != nil {
+		return nil, err
+	}
+	ps.audit(ctx, caller, "report."+status, report.PostId, fmt.Sprintf("report %d", report.Id))
+
+	post, err := ps.repository.GetPostById(ctx, report.PostId)
+	if err != nil {
+		return nil, err
+	}
+	switch status {
+	case reportStatusActioned:
+		if post.HiddenReason != actionedHiddenReason {
+			err = ps.repository.SetHidden(ctx, post.Id, true, actionedHiddenReason)
+		}
+	case reportStatusDismissed:
+		err = ps.restoreAutoHidden(ctx, post)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return toPbReport(report), nil
+}
+
+// restoreAutoHidden shows a post hidden by the report threshold again once
+// the reports still awaiting review fall below it.
+func (ps *PostService) restoreAutoHidden(ctx context.Context, post repository.Post) error {
+	if !post.IsHidden || post.HiddenReason != autoHiddenReason {
+		return nil
+	}
+
+	count, err := ps.repository.CountReports(ctx, post.Id, activeReportStatuses)
+	if err != nil {
+		return err
+	}
+	if count >= ps.reportHideThreshold {
+		return nil
+	}
+
+	err = ps.repository.SetHidden(ctx, post.Id, false, "")
+	if err != nil {
+		return err
+	}
+	ps.audit(ctx, systemCaller, "post.auto_unhide", post.Id, fmt.Sprintf("%d reports", count))
+	return nil
+}
+
+func toPbReport(report repository.Report) *pb.Report {
+	return &pb.Report{
+		Id:         report.Id,
+		PostId:     report.PostId,
+		ReporterId: report.ReporterId,
+		Reason:     pb.ReportReason(enumValue(pb.ReportReason_value, report.Reason, "REPORT_REASON_")),
+		Comment:    report.Comment,
+		Status:     pb.ReportStatus(enumValue(pb.ReportStatus_value, report.Status, "REPORT_STATUS_")),
+		ReviewerId: report.ReviewerId,
+		CreatedAt:  timestamppb.New(report.CreatedAt),
+		UpdatedAt:  timestamppb.New(report.UpdatedAt),
+	}
+}
+
+// enumName turns REPORT_REASON_HATE_SPEECH into hate_speech as stored in the
+// database, unknown and zero values give an empty string.
+func enumName(names map[int32]string, value int32, prefix string) string {
+	name, ok := names[value]
+	if !ok || value == 0 {
+		return ""
+	}
+	return strings.ToLower(strings.TrimPrefix(name, prefix))
+}
+
+func enumValue(values map[string]int32, name string, prefix string) int32 {
+	return values[prefix+strings.ToUpper(name)]
+}
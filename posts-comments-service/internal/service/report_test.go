@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"social-network/posts-comments-service/internal/auth"
+	customerror "social-network/posts-comments-service/internal/errors"
+	"social-network/posts-comments-service/internal/repository"
+	pb "social-network/protos"
+	"testing"
+)
+
+func (f *fakeRepository) AddReport(_ context.Context, report repository.Report) error {
+	report.Id = int32(len(f.reports) + 1)
+	f.reports = append(f.reports, report)
+	return nil
+}
+
+func (f *fakeRepository) CountReports(_ context.Context, postId int32, statuses []string) (int, error) {
+	count := 0
+	for _, report := range f.reports {
+		if report.PostId == postId && slices.Contains(statuses, report.Status) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (f *fakeRepository) GetReportById(_ context.Context, id int32) (repository.Report, error) {
+	for _, report := range f.reports {
+		if report.Id == id {
+			return report, nil
+		}
+	}
+	return repository.Report{}, &customerror.NotFoundError{}
+}
+
+func (f *fakeRepository) UpdateReport(_ context.Context, report repository.Report) error {
+	f.reports[report.Id-1] = report
+	return nil
+}
+
+func (f *fakeRepository) SetHidden(_ context.Context, id int32, hidden bool, reason string) error {
+	post := f.posts[id]
+	post.IsHidden = hidden
+	post.HiddenReason = reason
+	f.posts[id] = post
+	return nil
+}
+
+var moderator = auth.Caller{UserId: 100, Role: auth.RoleModerator}
+
+func report(t *testing.T, ps *PostService, reporterId int32) {
+	t.Helper()
+	err := ps.ReportPost(context.Background(), &pb.ReportPostRequest{
+		PostId: 1,
+		Reason: pb.ReportReason_REPORT_REASON_SPAM,
+	}, auth.Caller{UserId: reporterId, Role: auth.RoleUser})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func setStatus(ps *PostService, reportId int32, status pb.ReportStatus) error {
+	_, err := ps.UpdateReportStatus(context.Background(), &pb.UpdateReportStatusRequest{
+		ReportId: reportId,
+		Status:   status,
+	}, moderator)
+	return err
+}
+
+func TestReportStatusTransitions(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		to      pb.ReportStatus
+		wantErr bool
+	}{
+		{name: "open to reviewing", from: reportStatusOpen, to: pb.ReportStatus_REPORT_STATUS_REVIEWING},
+		{name: "open to actioned", from: reportStatusOpen, to: pb.ReportStatus_REPORT_STATUS_ACTIONED},
+		{name: "open to dismissed", from: reportStatusOpen, to: pb.ReportStatus_REPORT_STATUS_DISMISSED},
+		{name: "reviewing back to open", from: reportStatusReviewing, to: pb.ReportStatus_REPORT_STATUS_OPEN},
+		{name: "reviewing to dismissed", from: reportStatusReviewing, to: pb.ReportStatus_REPORT_STATUS_DISMISSED},
+		{name: "open to open", from: reportStatusOpen, to: pb.ReportStatus_REPORT_STATUS_OPEN, wantErr: true},
+		{name: "actioned is final", from: reportStatusActioned, to: pb.ReportStatus_REPORT_STATUS_OPEN, wantErr: true},
+		{name: "dismissed is final", from: reportStatusDismissed, to: pb.ReportStatus_REPORT_STATUS_ACTIONED, wantErr: true},
+		{name: "unspecified status", from: reportStatusOpen, to: pb.ReportStatus_REPORT_STATUS_UNSPECIFIED, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepository(repository.Post{Id: 1, CreatorId: 7})
+			repo.reports = []repository.Report{{Id: 1, PostId: 1, ReporterId: 8, Reason: "spam", Status: tt.from}}
+			ps := &PostService{repository: repo, reportHideThreshold: 3}
+
+			err := setStatus(ps, 1, tt.to)
+			if tt.wantErr {
+				var invalid *customerror.InvalidArgumentError
+				if !errors.As(err, &invalid) {
+					t.Fatalf("error = %v, want an invalid argument", err)
+				}
+				if repo.reports[0].Status != tt.from {
+					t.Errorf("status = %s, want it unchanged", repo.reports[0].Status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if repo.reports[0].ReviewerId != moderator.UserId {
+				t.Errorf("reviewer = %d, want %d", repo.reports[0].ReviewerId, moderator.UserId)
+			}
+		})
+	}
+}
+
+func TestUpdateReportStatusRequiresModerator(t *testing.T) {
+	repo := newFakeRepository(repository.Post{Id: 1, CreatorId: 7})
+	repo.reports = []repository.Report{{Id: 1, PostId: 1, ReporterId: 8, Reason: "spam", Status: reportStatusOpen}}
+	ps := &PostService{repository: repo}
+
+	_, err := ps.UpdateReportStatus(context.Background(), &pb.UpdateReportStatusRequest{
+		ReportId: 1,
+		Status:   pb.ReportStatus_REPORT_STATUS_DISMISSED,
+	}, auth.Caller{UserId: 8, Role: auth.RoleUser})
+
+	var denied *customerror.PermissionDeniedError
+	if !errors.As(err, &denied) {
+		t.Fatalf("error = %v, want permission denied", err)
+	}
+}
+
+func TestReportThresholdHidesPost(t *testing.T) {
+	repo := newFakeRepository(repository.Post{Id: 1, CreatorId: 7})
+	ps := &PostService{repository: repo, reportHideThreshold: 3}
+
+	report(t, ps, 10)
+	report(t, ps, 11)
+	if repo.posts[1].IsHidden {
+		t.Fatal("post is hidden below the threshold")
+	}
+
+	report(t, ps, 12)
+	if !repo.posts[1].IsHidden || repo.posts[1].HiddenReason != autoHiddenReason {
+		t.Fatalf("post = %+v, want it hidden by reports", repo.posts[1])
+	}
+
+	// dismissing one report brings the active ones below the threshold
+	err := setStatus(ps, 1, pb.ReportStatus_REPORT_STATUS_DISMISSED)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.posts[1].IsHidden {
+		t.Error("post stays hidden after its reports fell below the threshold")
+	}
+}
+
+func TestActionedReportKeepsPostHidden(t *testing.T) {
+	repo := newFakeRepository(repository.Post{Id: 1, CreatorId: 7})
+	ps := &PostService{repository: repo, reportHideThreshold: 2}
+
+	report(t, ps, 10)
+	report(t, ps, 11)
+
+	err := setStatus(ps, 1, pb.ReportStatus_REPORT_STATUS_ACTIONED)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.posts[1].HiddenReason != actionedHiddenReason {
+		t.Fatalf("hidden reason = %q, want %q", repo.posts[1].HiddenReason, actionedHiddenReason)
+	}
+
+	err = setStatus(ps, 2, pb.ReportStatus_REPORT_STATUS_DISMISSED)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !repo.posts[1].IsHidden {
+		t.Error("dismissing the remaining report brought back an actioned post")
+	}
+}
+
+func TestReportThresholdDisabled(t *testing.T) {
+	repo := newFakeRepository(repository.Post{Id: 1, CreatorId: 7})
+	ps := &PostService{repository: repo}
+
+	for id := int32(10); id < 20; id++ {
+		report(t, ps, id)
+	}
+	if repo.posts[1].IsHidden {
+		t.Error("post is hidden with the threshold disabled")
+	}
+}
+
+func TestReportOwnPost(t *testing.T) {
+	repo := newFakeRepository(repository.Post{Id: 1, CreatorId: 7})
+	ps := &PostService{repository: repo, reportHideThreshold: 1}
+
+	err := ps.ReportPost(context.Background(), &pb.ReportPostRequest{
+		PostId: 1,
+		Reason: pb.ReportReason_REPORT_REASON_SPAM,
+	}, auth.Caller{UserId: 7, Role: auth.RoleUser})
+
+	var invalid *customerror.InvalidArgumentError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("error = %v, want an invalid argument", err)
+	}
+	if len(repo.reports) != 0 {
+		t.Error("report was stored")
+	}
+}
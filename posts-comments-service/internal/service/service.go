@@ -1,31 +1,51 @@
 package service
 
 import (
+	"context"
+	"fmt"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	"social-network/pkg/logger"
+	"social-network/pkg/validation"
+	"social-network/posts-comments-service/internal/auth"
+	"social-network/posts-comments-service/internal/config"
+	customerror "social-network/posts-comments-service/internal/errors"
 	"social-network/posts-comments-service/internal/repository"
 	pb "social-network/protos"
 	"time"
 )
 
 type Repository interface {
-	AddPost(post repository.Post) error
-	DeletePost(id int32) error
-	UpdatePost(post repository.Post) error
-	GetPostById(id int32) (repository.Post, error)
-	GetAllPosts(limit int32, offset int32, userId int32) ([]repository.Post, error)
+	AddPost(ctx context.Context, post repository.Post) error
+	DeletePost(ctx context.Context, id int32) error
+	UpdatePost(ctx context.Context, post repository.Post, columns []string) error
+	GetPostById(ctx context.Context, id int32) (repository.Post, error)
+	GetAllPosts(ctx context.Context, limit int32, offset int32, userId int32, excludeUserIds []int32) ([]repository.Post, error)
+	SetHidden(ctx context.Context, id int32, hidden bool, reason string) error
+	WriteAudit(ctx context.Context, entry repository.AuditEntry) error
+	AddReport(ctx context.Context, report repository.Report) error
+	CountReports(ctx context.Context, postId int32, statuses []string) (int, error)
+	ListReports(ctx context.Context, status string, limit int32, offset int32) ([]repository.Report, int, error)
+	GetReportById(ctx context.Context, id int32) (repository.Report, error)
+	UpdateReport(ctx context.Context, report repository.Report) error
+	DeleteUserContent(ctx context.Context, userId int32) error
+	GetPostsByCreator(ctx context.Context, userId int32) ([]repository.Post, error)
+	GetReportsByReporter(ctx context.Context, userId int32) ([]repository.Report, error)
+	CountPublicPosts(ctx context.Context, userId int32) (int, error)
 }
 
 type PostService struct {
-	repository Repository
+	repository          Repository
+	reportHideThreshold int
 }
 
-func NewPostService(repo Repository) *PostService {
+func NewPostService(repo Repository, cfg *config.Config) *PostService {
 	return &PostService{
-		repo,
+		repository:          repo,
+		reportHideThreshold: cfg.ReportHideThreshold,
 	}
 }
 
-func (ps *PostService) AddPost(post *pb.PostEssential, userId int32) error {
+func (ps *PostService) AddPost(ctx context.Context, post *pb.PostEssential, userId int32) error {
 	dbPost := repository.Post{
 		Name:        post.Name,
 		Description: post.Description,
@@ -36,47 +56,118 @@ func (ps *PostService) AddPost(post *pb.PostEssential, userId int32) error {
 		Tags:        post.Tags,
 	}
 
-	return ps.repository.AddPost(dbPost)
+	err := validation.Struct(dbPost)
+	if err != nil {
+		return err
+	}
+
+	err = ps.repository.AddPost(ctx, dbPost)
+	if err != nil {
+		return err
+	}
+	postsCreated.Inc()
+	return nil
 }
 
-func (ps *PostService) DeletePost(postId int32) error {
-	return ps.repository.DeletePost(postId)
+// DeletePost lets the author delete the post, moderators can delete any post.
+func (ps *PostService) DeletePost(ctx context.Context, postId int32, caller auth.Caller) error {
+	post, err := ps.repository.GetPostById(ctx, postId)
+	if err != nil {
+		return err
+	}
+
+	if !caller.Owns(post.CreatorId) {
+		if !caller.IsModerator() {
+			return &customerror.PermissionDeniedError{}
+		}
+		ps.audit(ctx, caller, "post.delete", postId, fmt.Sprintf("author %d", post.CreatorId))
+	}
+	return ps.repository.DeletePost(ctx, postId)
+}
+
+func (ps *PostService) HidePost(ctx context.Context, req *pb.HidePostRequest, caller auth.Caller) error {
+	if !caller.IsModerator() {
+		return &customerror.PermissionDeniedError{}
+	}
+
+	_, err := ps.repository.GetPostById(ctx, req.GetPostId())
+	if err != nil {
+		return err
+	}
+
+	reason := req.GetReason()
+	if !req.GetHidden() {
+		reason = ""
+	}
+	err = ps.repository.SetHidden(ctx, req.GetPostId(), req.GetHidden(), reason)
+	if err != nil {
+		return err
+	}
+
+	action := "post.hide"
+	if !req.GetHidden() {
+		action = "post.unhide"
+	}
+	ps.audit(ctx, caller, action, req.GetPostId(), req.GetReason())
+	return nil
 }
 
-func (ps *PostService) UpdatePost(post *pb.PostWithNoUser) error {
+func (ps *PostService) UpdatePost(ctx context.Context, req *pb.UpdatePostRequest, caller auth.Caller) error {
+	mask := req.GetUpdateMask()
+	if len(mask.GetPaths()) == 0 {
+		return &customerror.InvalidArgumentError{Message: "update_mask is empty"}
+	}
+	if !mask.IsValid(&pb.PostEssential{}) {
+		return &customerror.InvalidArgumentError{Message: "update_mask contains unknown fields"}
+	}
+	mask.Normalize()
+
+	post := req.GetPost()
 	dbPost := repository.Post{
-		Id:          post.Id,
-		Name:        post.Name,
-		Description: post.Description,
+		Id:          req.GetId(),
+		Name:        post.GetName(),
+		Description: post.GetDescription(),
 		UpdatedAt:   time.Now(),
-		IsPrivate:   post.IsPrivate,
-		Tags:        post.Tags,
+		IsPrivate:   post.GetIsPrivate(),
+		Tags:        post.GetTags(),
+	}
+	if dbPost.Tags == nil {
+		dbPost.Tags = []string{}
 	}
 
-	return ps.repository.UpdatePost(dbPost)
+	err := validation.StructPartial(dbPost, mask.GetPaths()...)
+	if err != nil {
+		return err
+	}
+
+	oldPost, err := ps.repository.GetPostById(ctx, req.GetId())
+	if err != nil {
+		return err
+	}
+	if !caller.Owns(oldPost.CreatorId) {
+		return &customerror.PermissionDeniedError{}
+	}
+
+	// PostEssential field names match the posts table columns
+	columns := append(mask.GetPaths(), "updated_at")
+	return ps.repository.UpdatePost(ctx, dbPost, columns)
 }
 
-func (ps *PostService) GetPostById(id int32) (*pb.Post, error) {
-	post, err := ps.repository.GetPostById(id)
+// GetPostById hides posts taken down by moderators from everyone but the author and moderators.
+func (ps *PostService) GetPostById(ctx context.Context, id int32, caller auth.Caller) (*pb.Post, error) {
+	post, err := ps.repository.GetPostById(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	grpcPost := &pb.Post{
-		Name:        post.Name,
-		Description: post.Description,
-		CreatedAd:   timestamppb.New(post.CreatedAt),
-		UpdatedAt:   timestamppb.New(post.UpdatedAt),
-		IsPrivate:   post.IsPrivate,
-		Tags:        post.Tags,
-		Id:          post.Id,
-		UserId:      post.CreatorId,
+	if post.IsHidden && !caller.Owns(post.CreatorId) && !caller.IsModerator() {
+		return nil, &customerror.NotFoundError{}
 	}
 
-	return grpcPost, nil
+	return toPbPost(post), nil
 }
 
-func (ps *PostService) GetAllPosts(pagination *pb.Pagination, userId int32) (*pb.AllPosts, error) {
-	posts, err := ps.repository.GetAllPosts(pagination.PageSize, pagination.PageIndex, userId)
+func (ps *PostService) GetAllPosts(ctx context.Context, pagination *pb.Pagination, userId int32) (*pb.AllPosts, error) {
+	posts, err := ps.repository.GetAllPosts(ctx, pagination.PageSize, pagination.PageIndex, userId, pagination.ExcludeUserIds)
 	if err != nil {
 		return nil, err
 	}
@@ -84,17 +175,39 @@ func (ps *PostService) GetAllPosts(pagination *pb.Pagination, userId int32) (*pb
 	var allPorts pb.AllPosts
 	allPorts.Posts = make([]*pb.Post, 0)
 	for _, post := range posts {
-		allPorts.Posts = append(allPorts.Posts, &pb.Post{
-			Name:        post.Name,
-			Id:          post.Id,
-			Description: post.Description,
-			UserId:      post.CreatorId,
-			CreatedAd:   timestamppb.New(post.CreatedAt),
-			UpdatedAt:   timestamppb.New(post.UpdatedAt),
-			IsPrivate:   post.IsPrivate,
-			Tags:        post.Tags,
-		})
+		allPorts.Posts = append(allPorts.Posts, toPbPost(post))
 	}
 
 	return &allPorts, nil
 }
+
+func toPbPost(post repository.Post) *pb.Post {
+	return &pb.Post{
+		Name:        post.Name,
+		Description: post.Description,
+		CreatedAd:   timestamppb.New(post.CreatedAt),
+		UpdatedAt:   timestamppb.New(post.UpdatedAt),
+		IsPrivate:   post.IsPrivate,
+		Tags:        post.Tags,
+		Id:          post.Id,
+		UserId:      post.CreatorId,
+		IsHidden:    post.IsHidden,
+	}
+}
+
+// audit records the action even when the caller went away after the change
+// was made. An entry that cannot be written is logged in full instead, it
+// must not fail the action.
+func (ps *PostService) audit(ctx context.Context, caller auth.Caller, action string, postId int32, details string) {
+	err := ps.repository.WriteAudit(context.WithoutCancel(ctx), repository.AuditEntry{
+		ActorId:   caller.UserId,
+		ActorRole: caller.Role,
+		Action:    action,
+		TargetId:  postId,
+		Details:   details,
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "audit entry lost", "actor_id", caller.UserId, "actor_role", caller.Role,
+			"action", action, "post_id", postId, "details", details, "error", err)
+	}
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"social-network/posts-comments-service/internal/auth"
+	customerror "social-network/posts-comments-service/internal/errors"
+	"social-network/posts-comments-service/internal/repository"
+	pb "social-network/protos"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// fakeRepository keeps posts and reports in memory and records the columns of
+// the last update. Methods the tests do not need panic through the embedded nil interface.
+type fakeRepository struct {
+	Repository
+	posts   map[int32]repository.Post
+	reports []repository.Report
+	columns []string
+}
+
+func newFakeRepository(posts ...repository.Post) *fakeRepository {
+	repo := &fakeRepository{posts: make(map[int32]repository.Post)}
+	for _, post := range posts {
+		repo.posts[post.Id] = post
+	}
+	return repo
+}
+
+func (f *fakeRepository) GetPostById(_ context.Context, id int32) (repository.Post, error) {
+	post, ok := f.posts[id]
+	if !ok {
+		return repository.Post{}, &customerror.NotFoundError{}
+	}
+	return post, nil
+}
+
+func (f *fakeRepository) UpdatePost(_ context.Context, post repository.Post, columns []string) error {
+	f.posts[post.Id] = post
+	f.columns = columns
+	return nil
+}
+
+func (f *fakeRepository) WriteAudit(context.Context, repository.AuditEntry) error {
+	return nil
+}
+
+func TestUpdatePostMask(t *testing.T) {
+	author := auth.Caller{UserId: 7, Role: auth.RoleUser}
+
+	tests := []struct {
+		name        string
+		caller      auth.Caller
+		paths       []string
+		post        *pb.PostEssential
+		wantColumns []string
+		wantErr     any
+	}{
+		{
+			name:        "only the masked columns are written",
+			caller:      author,
+			paths:       []string{"name"},
+			post:        &pb.PostEssential{Name: "new name", Description: "ignored"},
+			wantColumns: []string{"name", "updated_at"},
+		},
+		{
+			name:        "clearing an optional field",
+			caller:      author,
+			paths:       []string{"tags", "description"},
+			post:        &pb.PostEssential{},
+			wantColumns: []string{"description", "tags", "updated_at"},
+		},
+		{
+			name:    "empty mask",
+			caller:  author,
+			post:    &pb.PostEssential{Name: "new name"},
+			wantErr: new(*customerror.InvalidArgumentError),
+		},
+		{
+			name:    "unknown path",
+			caller:  author,
+			paths:   []string{"creator_id"},
+			post:    &pb.PostEssential{},
+			wantErr: new(*customerror.InvalidArgumentError),
+		},
+		{
+			name:    "masked field is validated",
+			caller:  author,
+			paths:   []string{"name"},
+			post:    &pb.PostEssential{},
+			wantErr: new(*customerror.ValidationError),
+		},
+		{
+			name:        "unmasked field is not validated",
+			caller:      author,
+			paths:       []string{"description"},
+			post:        &pb.PostEssential{Description: "text"},
+			wantColumns: []string{"description", "updated_at"},
+		},
+		{
+			name:    "not the author",
+			caller:  auth.Caller{UserId: 8, Role: auth.RoleModerator},
+			paths:   []string{"name"},
+			post:    &pb.PostEssential{Name: "new name"},
+			wantErr: new(*customerror.PermissionDeniedError),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRepository(repository.Post{Id: 1, Name: "post", CreatorId: 7})
+			ps := &PostService{repository: repo}
+
+			err := ps.UpdatePost(context.Background(), &pb.UpdatePostRequest{
+				Id:         1,
+				Post:       tt.post,
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: tt.paths},
+			}, tt.caller)
+			if tt.wantErr != nil {
+				if !errors.As(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %T", err, tt.wantErr)
+				}
+				if repo.columns != nil {
+					t.Errorf("post was updated with %v", repo.columns)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(repo.columns, tt.wantColumns) {
+				t.Errorf("columns = %v, want %v", repo.columns, tt.wantColumns)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"social-network/posts-comments-service/internal/auth"
+	customerror "social-network/posts-comments-service/internal/errors"
+	pb "social-network/protos"
+)
+
+// DeleteUserContent is called by user-service when an account is deleted,
+// it is safe to call again for the same user.
+func (ps *PostService) DeleteUserContent(ctx context.Context, req *pb.DeleteUserContentRequest, caller auth.Caller) error {
+	if !caller.IsService() && caller.Role != auth.RoleAdmin {
+		return &customerror.PermissionDeniedError{}
+	}
+	if req.GetUserId() <= 0 {
+		return &customerror.InvalidArgumentError{Message: "user_id is required"}
+	}
+
+	err := ps.repository.DeleteUserContent(ctx, req.GetUserId())
+	if err != nil {
+		return err
+	}
+	ps.audit(ctx, caller, "user.content_delete", req.GetUserId(), "")
+	return nil
+}
+
+// ExportUserContent returns the caller's posts, private and hidden ones included, and the reports they filed.
+func (ps *PostService) ExportUserContent(ctx context.Context, caller auth.Caller) (*pb.UserContent, error) {
+	posts, err := ps.repository.GetPostsByCreator(ctx, caller.UserId)
+	if err != nil {
+		return nil, err
+	}
+	reports, err := ps.repository.GetReportsByReporter(ctx, caller.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	content := &pb.UserContent{
+		Posts:   make([]*pb.Post, 0, len(posts)),
+		Reports: make([]*pb.Report, 0, len(reports)),
+	}
+	for _, post := range posts {
+		content.Posts = append(content.Posts, toPbPost(post))
+	}
+	for _, report := range reports {
+		content.Reports = append(content.Reports, toPbReport(report))
+	}
+	return content, nil
+}
+
+func (ps *PostService) GetUserStats(ctx context.Context, req *pb.UserStatsRequest) (*pb.UserStats, error) {
+	count, err := ps.repository.CountPublicPosts(ctx, req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UserStats{PostsCount: int32(count)}, nil
+}
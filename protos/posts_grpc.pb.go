@@ -25,6 +25,13 @@ const (
 	PostsService_GetPostById_FullMethodName          = "/PostsService/GetPostById"
 	PostsService_UpdatePost_FullMethodName           = "/PostsService/UpdatePost"
 	PostsService_GetAllPostsPaginated_FullMethodName = "/PostsService/GetAllPostsPaginated"
+	PostsService_HidePost_FullMethodName             = "/PostsService/HidePost"
+	PostsService_ReportPost_FullMethodName           = "/PostsService/ReportPost"
+	PostsService_ListReports_FullMethodName          = "/PostsService/ListReports"
+	PostsService_UpdateReportStatus_FullMethodName   = "/PostsService/UpdateReportStatus"
+	PostsService_DeleteUserContent_FullMethodName    = "/PostsService/DeleteUserContent"
+	PostsService_ExportUserContent_FullMethodName    = "/PostsService/ExportUserContent"
+	PostsService_GetUserStats_FullMethodName         = "/PostsService/GetUserStats"
 )
 
 // PostsServiceClient is the client API for PostsService service.
@@ -34,8 +41,16 @@ type PostsServiceClient interface {
 	AddPost(ctx context.Context, in *PostEssential, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	DeletePost(ctx context.Context, in *PostId, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	GetPostById(ctx context.Context, in *PostId, opts ...grpc.CallOption) (*Post, error)
-	UpdatePost(ctx context.Context, in *PostWithNoUser, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	UpdatePost(ctx context.Context, in *UpdatePostRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	GetAllPostsPaginated(ctx context.Context, in *Pagination, opts ...grpc.CallOption) (*AllPosts, error)
+	HidePost(ctx context.Context, in *HidePostRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ReportPost(ctx context.Context, in *ReportPostRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ListReports(ctx context.Context, in *ListReportsRequest, opts ...grpc.CallOption) (*ReportList, error)
+	UpdateReportStatus(ctx context.Context, in *UpdateReportStatusRequest, opts ...grpc.CallOption) (*Report, error)
+	// DeleteUserContent is called by user-service once an account is deleted
+	DeleteUserContent(ctx context.Context, in *DeleteUserContentRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ExportUserContent(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*UserContent, error)
+	GetUserStats(ctx context.Context, in *UserStatsRequest, opts ...grpc.CallOption) (*UserStats, error)
 }
 
 type postsServiceClient struct {
@@ -76,7 +91,7 @@ func (c *postsServiceClient) GetPostById(ctx context.Context, in *PostId, opts .
 	return out, nil
 }
 
-func (c *postsServiceClient) UpdatePost(ctx context.Context, in *PostWithNoUser, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+func (c *postsServiceClient) UpdatePost(ctx context.Context, in *UpdatePostRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(emptypb.Empty)
 	err := c.cc.Invoke(ctx, PostsService_UpdatePost_FullMethodName, in, out, cOpts...)
@@ -96,6 +111,76 @@ func (c *postsServiceClient) GetAllPostsPaginated(ctx context.Context, in *Pagin
 	return out, nil
 }
 
+func (c *postsServiceClient) HidePost(ctx context.Context, in *HidePostRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, PostsService_HidePost_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postsServiceClient) ReportPost(ctx context.Context, in *ReportPostRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, PostsService_ReportPost_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postsServiceClient) ListReports(ctx context.Context, in *ListReportsRequest, opts ...grpc.CallOption) (*ReportList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReportList)
+	err := c.cc.Invoke(ctx, PostsService_ListReports_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postsServiceClient) UpdateReportStatus(ctx context.Context, in *UpdateReportStatusRequest, opts ...grpc.CallOption) (*Report, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Report)
+	err := c.cc.Invoke(ctx, PostsService_UpdateReportStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postsServiceClient) DeleteUserContent(ctx context.Context, in *DeleteUserContentRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, PostsService_DeleteUserContent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postsServiceClient) ExportUserContent(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*UserContent, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UserContent)
+	err := c.cc.Invoke(ctx, PostsService_ExportUserContent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postsServiceClient) GetUserStats(ctx context.Context, in *UserStatsRequest, opts ...grpc.CallOption) (*UserStats, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UserStats)
+	err := c.cc.Invoke(ctx, PostsService_GetUserStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PostsServiceServer is the server API for PostsService service.
 // All implementations must embed UnimplementedPostsServiceServer
 // for forward compatibility.
@@ -103,8 +188,16 @@ type PostsServiceServer interface {
 	AddPost(context.Context, *PostEssential) (*emptypb.Empty, error)
 	DeletePost(context.Context, *PostId) (*emptypb.Empty, error)
 	GetPostById(context.Context, *PostId) (*Post, error)
-	UpdatePost(context.Context, *PostWithNoUser) (*emptypb.Empty, error)
+	UpdatePost(context.Context, *UpdatePostRequest) (*emptypb.Empty, error)
 	GetAllPostsPaginated(context.Context, *Pagination) (*AllPosts, error)
+	HidePost(context.Context, *HidePostRequest) (*emptypb.Empty, error)
+	ReportPost(context.Context, *ReportPostRequest) (*emptypb.Empty, error)
+	ListReports(context.Context, *ListReportsRequest) (*ReportList, error)
+	UpdateReportStatus(context.Context, *UpdateReportStatusRequest) (*Report, error)
+	// DeleteUserContent is called by user-service once an account is deleted
+	DeleteUserContent(context.Context, *DeleteUserContentRequest) (*emptypb.Empty, error)
+	ExportUserContent(context.Context, *emptypb.Empty) (*UserContent, error)
+	GetUserStats(context.Context, *UserStatsRequest) (*UserStats, error)
 	mustEmbedUnimplementedPostsServiceServer()
 }
 
@@ -124,12 +217,33 @@ func (UnimplementedPostsServiceServer) DeletePost(context.Context, *PostId) (*em
 func (UnimplementedPostsServiceServer) GetPostById(context.Context, *PostId) (*Post, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetPostById not implemented")
 }
-func (UnimplementedPostsServiceServer) UpdatePost(context.Context, *PostWithNoUser) (*emptypb.Empty, error) {
+func (UnimplementedPostsServiceServer) UpdatePost(context.Context, *UpdatePostRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdatePost not implemented")
 }
 func (UnimplementedPostsServiceServer) GetAllPostsPaginated(context.Context, *Pagination) (*AllPosts, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetAllPostsPaginated not implemented")
 }
+func (UnimplementedPostsServiceServer) HidePost(context.Context, *HidePostRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HidePost not implemented")
+}
+func (UnimplementedPostsServiceServer) ReportPost(context.Context, *ReportPostRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportPost not implemented")
+}
+func (UnimplementedPostsServiceServer) ListReports(context.Context, *ListReportsRequest) (*ReportList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReports not implemented")
+}
+func (UnimplementedPostsServiceServer) UpdateReportStatus(context.Context, *UpdateReportStatusRequest) (*Report, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateReportStatus not implemented")
+}
+func (UnimplementedPostsServiceServer) DeleteUserContent(context.Context, *DeleteUserContentRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteUserContent not implemented")
+}
+func (UnimplementedPostsServiceServer) ExportUserContent(context.Context, *emptypb.Empty) (*UserContent, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportUserContent not implemented")
+}
+func (UnimplementedPostsServiceServer) GetUserStats(context.Context, *UserStatsRequest) (*UserStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserStats not implemented")
+}
 func (UnimplementedPostsServiceServer) mustEmbedUnimplementedPostsServiceServer() {}
 func (UnimplementedPostsServiceServer) testEmbeddedByValue()                      {}
 
@@ -206,7 +320,7 @@ func _PostsService_GetPostById_Handler(srv interface{}, ctx context.Context, dec
 }
 
 func _PostsService_UpdatePost_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(PostWithNoUser)
+	in := new(UpdatePostRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -218,7 +332,7 @@ func _PostsService_UpdatePost_Handler(srv interface{}, ctx context.Context, dec
 		FullMethod: PostsService_UpdatePost_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(PostsServiceServer).UpdatePost(ctx, req.(*PostWithNoUser))
+		return srv.(PostsServiceServer).UpdatePost(ctx, req.(*UpdatePostRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -241,6 +355,132 @@ func _PostsService_GetAllPostsPaginated_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PostsService_HidePost_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HidePostRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostsServiceServer).HidePost(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostsService_HidePost_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostsServiceServer).HidePost(ctx, req.(*HidePostRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PostsService_ReportPost_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportPostRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostsServiceServer).ReportPost(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostsService_ReportPost_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostsServiceServer).ReportPost(ctx, req.(*ReportPostRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PostsService_ListReports_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReportsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostsServiceServer).ListReports(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostsService_ListReports_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostsServiceServer).ListReports(ctx, req.(*ListReportsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PostsService_UpdateReportStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateReportStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostsServiceServer).UpdateReportStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostsService_UpdateReportStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostsServiceServer).UpdateReportStatus(ctx, req.(*UpdateReportStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PostsService_DeleteUserContent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteUserContentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostsServiceServer).DeleteUserContent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostsService_DeleteUserContent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostsServiceServer).DeleteUserContent(ctx, req.(*DeleteUserContentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PostsService_ExportUserContent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostsServiceServer).ExportUserContent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostsService_ExportUserContent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostsServiceServer).ExportUserContent(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PostsService_GetUserStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostsServiceServer).GetUserStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostsService_GetUserStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostsServiceServer).GetUserStats(ctx, req.(*UserStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // PostsService_ServiceDesc is the grpc.ServiceDesc for PostsService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -268,6 +508,34 @@ var PostsService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetAllPostsPaginated",
 			Handler:    _PostsService_GetAllPostsPaginated_Handler,
 		},
+		{
+			MethodName: "HidePost",
+			Handler:    _PostsService_HidePost_Handler,
+		},
+		{
+			MethodName: "ReportPost",
+			Handler:    _PostsService_ReportPost_Handler,
+		},
+		{
+			MethodName: "ListReports",
+			Handler:    _PostsService_ListReports_Handler,
+		},
+		{
+			MethodName: "UpdateReportStatus",
+			Handler:    _PostsService_UpdateReportStatus_Handler,
+		},
+		{
+			MethodName: "DeleteUserContent",
+			Handler:    _PostsService_DeleteUserContent_Handler,
+		},
+		{
+			MethodName: "ExportUserContent",
+			Handler:    _PostsService_ExportUserContent_Handler,
+		},
+		{
+			MethodName: "GetUserStats",
+			Handler:    _PostsService_GetUserStats_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "posts.proto",
@@ -1,30 +1,87 @@
 package main
 
 import (
-	"github.com/joho/godotenv"
+	"fmt"
 	"go.uber.org/fx"
+	"os"
+	"social-network/pkg/certs"
+	"social-network/pkg/env"
+	"social-network/pkg/health"
+	"social-network/pkg/logger"
+	"social-network/pkg/serving"
+	"social-network/pkg/tracing"
 	"social-network/user-service/internal/app"
+	"social-network/user-service/internal/auth"
+	"social-network/user-service/internal/client"
 	"social-network/user-service/internal/config"
 	"social-network/user-service/internal/db"
-	"social-network/user-service/internal/logger"
+	"social-network/user-service/internal/mail"
+	"social-network/user-service/internal/outbox"
 	"social-network/user-service/internal/repository"
+	"social-network/user-service/internal/rpc"
 	"social-network/user-service/internal/server"
 	"social-network/user-service/internal/service"
+	"social-network/user-service/internal/storage"
+	"social-network/user-service/internal/worker"
+	"time"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "healthcheck" {
+		healthcheck()
+		return
+	}
+
 	addOpts := fx.Options(
+		env.Module,
+		logger.Module,
+		tracing.Module("user-service"),
+		certs.Module,
+		db.Module,
 		fx.Provide(
 			repository.NewUserRepository,
+			repository.NewTokenRepository,
+			repository.NewAuditRepository,
+			repository.NewRelationRepository,
+			repository.NewOutboxRepository,
+			client.NewPostsClient,
+			outbox.NewDispatcher,
+			mail.NewSender,
+			storage.NewStorage,
 			service.NewUserService,
 			config.NewConfig,
+			config.NewInternalTLS,
 			app.NewApp,
+			server.NewHealthChecker,
 			server.NewServer,
-			db.InitDb,
+			rpc.NewServer,
+			auth.NewVerifier,
 		),
+		serving.HTTPModule,
 		fx.Invoke(
-			godotenv.Load,
-			logger.InitLogger,
-			server.InvokeServer))
+			server.InvokeGrpcServer,
+			worker.InvokeWorkers))
 	fx.New(addOpts).Run()
 }
+
+// healthcheck exits with a non-zero code unless the running service is ready.
+// It presents the certificate of the service, which only accepts mutual TLS once it has one.
+func healthcheck() {
+	cfg := config.NewConfig()
+	var internal *certs.Reloader
+	if cfg.InternalTLS.Enabled() {
+		var err error
+		internal, err = certs.Load(cfg.InternalTLS)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "unhealthy:", err)
+			os.Exit(1)
+		}
+	}
+
+	url := certs.Scheme(internal) + "://localhost" + cfg.ServerAddr + "/readyz"
+	err := health.Probe(url, certs.ClientTLS(internal), 2*time.Second)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "unhealthy:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"social-network/pkg/logger"
+	"social-network/user-service/internal/repository"
+)
+
+func (app *App) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "DELETE /user-profile")
+
+	request := repository.AccountDeletionRequest{}
+	data, _ := io.ReadAll(r.Body)
+	err := json.Unmarshal(data, &request)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "bad request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	deletion, err := app.userService.ScheduleDeletion(r.Context(), caller(r).Login, &request)
+	if err != nil {
+		writeCredentialsChangeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(deletion)
+}
+
+func (app *App) RestoreAccount(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /user-profile/restore")
+
+	err := app.userService.CancelDeletion(r.Context(), caller(r).Login)
+	if err != nil {
+		writeCredentialsChangeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,196 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"social-network/pkg/logger"
+	customError "social-network/user-service/internal/errors"
+	"social-network/user-service/internal/repository"
+	"strconv"
+)
+
+const (
+	defaultUsersLimit = 20
+	maxUsersLimit     = 100
+)
+
+func (app *App) SearchUsers(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "GET /admin/users")
+
+	query := r.URL.Query()
+	limit, err := queryInt(query.Get("limit"), defaultUsersLimit)
+	if err != nil || limit <= 0 {
+		logger.ErrorContext(r.Context(), "bad limit")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	limit = min(limit, maxUsersLimit)
+
+	offset, err := queryInt(query.Get("offset"), 0)
+	if err != nil || offset < 0 {
+		logger.ErrorContext(r.Context(), "bad offset")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	page, err := app.userService.SearchUsers(r.Context(), query.Get("query"), limit, offset, actorFromRequest(r))
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(page)
+}
+
+func (app *App) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /admin/unlock")
+
+	request := repository.AdminUserRequest{}
+	data, _ := io.ReadAll(r.Body)
+	err := json.Unmarshal(data, &request)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "bad request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = app.userService.UnlockUser(r.Context(), &request, actorFromRequest(r))
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (app *App) ChangeRole(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "PUT /admin/role")
+
+	change := repository.RoleChange{}
+	data, _ := io.ReadAll(r.Body)
+	err := json.Unmarshal(data, &change)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "bad request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = app.userService.ChangeRole(r.Context(), &change, actorFromRequest(r))
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func writeAdminError(w http.ResponseWriter, err error) {
+	var validationErr *customError.ValidationError
+	var permissionDeniedErr *customError.PermissionDeniedError
+	var notFoundErr *customError.NotFoundUserError
+
+	switch {
+	case errors.As(err, &validationErr):
+		writeValidationError(w, validationErr)
+		return
+	case errors.As(err, &permissionDeniedErr):
+		w.WriteHeader(http.StatusForbidden)
+	case errors.As(err, &notFoundErr):
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	_, _ = fmt.Fprint(w, err.Error())
+}
+
+// actorFromRequest returns the caller the internal token of the request names.
+func actorFromRequest(r *http.Request) repository.Actor {
+	user := caller(r)
+	return repository.Actor{
+		Login: user.Login,
+		Role:  user.Role,
+	}
+}
+
+func (app *App) SuspendUser(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /admin/users/suspend")
+
+	request := repository.SuspendRequest{}
+	data, _ := io.ReadAll(r.Body)
+	err := json.Unmarshal(data, &request)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "bad request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = app.userService.SuspendUser(r.Context(), &request, actorFromRequest(r))
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (app *App) UnsuspendUser(w http.ResponseWriter, r *http.Request) {
+	app.handleAdminUserRequest(w, r, "POST /admin/users/unsuspend", app.userService.UnsuspendUser)
+}
+
+func (app *App) ForceLogout(w http.ResponseWriter, r *http.Request) {
+	app.handleAdminUserRequest(w, r, "POST /admin/users/logout", app.userService.ForceLogout)
+}
+
+func (app *App) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "DELETE /admin/users")
+
+	request := repository.AdminUserRequest{Login: r.URL.Query().Get("login")}
+	err := app.userService.DeleteUser(r.Context(), &request, actorFromRequest(r))
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *App) RequeueOutbox(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /admin/outbox/requeue")
+
+	requeued, err := app.userService.RequeueParkedEvents(r.Context(), actorFromRequest(r))
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(requeued)
+}
+
+func (app *App) handleAdminUserRequest(w http.ResponseWriter, r *http.Request, route string,
+	action func(context.Context, *repository.AdminUserRequest, repository.Actor) error) {
+	logger.InfoContext(r.Context(), route)
+
+	request := repository.AdminUserRequest{}
+	data, _ := io.ReadAll(r.Body)
+	err := json.Unmarshal(data, &request)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "bad request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = action(r.Context(), &request, actorFromRequest(r))
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func queryInt(value string, fallback int) (int, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(value)
+}
@@ -6,113 +6,193 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"social-network/pkg/logger"
+	"social-network/user-service/internal/auth"
+	"social-network/user-service/internal/config"
 	customError "social-network/user-service/internal/errors"
-	"social-network/user-service/internal/logger"
 	"social-network/user-service/internal/repository"
 	"social-network/user-service/internal/service"
 )
 
 type App struct {
-	userService service.UserServiceInterface
+	userService   service.UserServiceInterface
+	avatarMaxSize int64
 }
 
-func NewApp(userService service.UserServiceInterface) *App {
+func NewApp(userService service.UserServiceInterface, cfg *config.Config) *App {
 	return &App{
-		userService: userService,
+		userService:   userService,
+		avatarMaxSize: cfg.AvatarMaxSize,
 	}
 }
 
-func (app *App) Register(w http.ResponseWriter, r *http.Request) {
-	logger.Info("POST /register")
+// caller returns the user the api-gateway proxied the request for, as
+// authenticated by auth.Middleware. Anonymous requests have no login.
+func caller(r *http.Request) auth.Caller {
+	caller, _ := auth.FromContext(r.Context())
+	return caller
+}
+
+func (app *App) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /user-profile/password")
 
-	user := repository.User{}
+	change := repository.PasswordChange{}
 	data, _ := io.ReadAll(r.Body)
-	err := json.Unmarshal(data, &user)
+	err := json.Unmarshal(data, &change)
 	if err != nil {
-		logger.Error(fmt.Sprintf("bad request"))
+		logger.ErrorContext(r.Context(), "bad request")
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	token, err := app.userService.Register(&user)
+	err = app.userService.ChangePassword(r.Context(), caller(r).Login, &change)
 	if err != nil {
-		var alreadyExists *customError.LoginAlreadyTakenError
-		w.WriteHeader(http.StatusBadRequest)
-
-		if errors.As(err, &alreadyExists) {
-			_, _ = fmt.Fprint(w, err.Error())
-		}
+		writeCredentialsChangeError(w, err)
 		return
 	}
-	_ = json.NewEncoder(w).Encode(token)
 	w.WriteHeader(http.StatusOK)
 }
 
-func (app *App) Login(w http.ResponseWriter, r *http.Request) {
-	logger.Info("POST /login")
+func (app *App) ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /user-profile/email")
 
-	user := repository.User{}
+	change := repository.EmailChange{}
 	data, _ := io.ReadAll(r.Body)
-	err := json.Unmarshal(data, &user)
+	err := json.Unmarshal(data, &change)
 	if err != nil {
-		logger.Error(fmt.Sprintf("bad request"))
+		logger.ErrorContext(r.Context(), "bad request")
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	token, err := app.userService.Login(&user)
+	err = app.userService.ChangeEmail(r.Context(), caller(r).Login, &change)
 	if err != nil {
-		var notFoundError *customError.NotFoundUserError
+		writeCredentialsChangeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func writeCredentialsChangeError(w http.ResponseWriter, err error) {
+	var validationErr *customError.ValidationError
+	var wrongPasswordErr *customError.WrongPasswordError
+	var notFoundErr *customError.NotFoundUserError
+
+	switch {
+	case errors.As(err, &validationErr):
+		writeValidationError(w, validationErr)
+		return
+	case errors.As(err, &wrongPasswordErr):
+		w.WriteHeader(http.StatusForbidden)
+	case errors.As(err, &notFoundErr):
 		w.WriteHeader(http.StatusBadRequest)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	_, _ = fmt.Fprint(w, err.Error())
+}
 
-		if errors.As(err, &notFoundError) {
-			_, _ = fmt.Fprint(w, err.Error())
-		}
+func writeValidationError(w http.ResponseWriter, err *customError.ValidationError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(struct {
+		Message string                   `json:"message"`
+		Fields  []customError.FieldError `json:"fields"`
+	}{
+		Message: err.Error(),
+		Fields:  err.Fields,
+	})
+}
+
+func (app *App) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /verify-email")
+
+	verification := repository.EmailVerification{}
+	data, _ := io.ReadAll(r.Body)
+	err := json.Unmarshal(data, &verification)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "bad request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = app.userService.VerifyEmail(r.Context(), &verification)
+	if err != nil {
+		writeTokenError(w, err)
 		return
 	}
-	_ = json.NewEncoder(w).Encode(token)
 	w.WriteHeader(http.StatusOK)
 }
 
-func (app *App) GetUserProfile(w http.ResponseWriter, r *http.Request) {
-	logger.Info("GET /user-profile")
+func (app *App) ResendEmailVerification(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /verify-email/resend")
 
-	user, err := app.userService.GetUserProfile(r.Header.Get("login"))
+	err := app.userService.ResendEmailVerification(r.Context(), caller(r).Login)
 	if err != nil {
 		var notFoundError *customError.NotFoundUserError
-		w.WriteHeader(http.StatusBadRequest)
-
 		if errors.As(err, &notFoundError) {
+			w.WriteHeader(http.StatusBadRequest)
 			_, _ = fmt.Fprint(w, err.Error())
+			return
 		}
+		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(*user)
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(http.StatusAccepted)
 }
 
-func (app *App) UpdateUserProfile(w http.ResponseWriter, r *http.Request) {
-	logger.Info("PUT /user-profile")
+func (app *App) RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /password-reset/request")
 
-	user := repository.User{}
+	request := repository.PasswordResetRequest{}
 	data, _ := io.ReadAll(r.Body)
-	err := json.Unmarshal(data, &user)
+	err := json.Unmarshal(data, &request)
 	if err != nil {
-		logger.Error(fmt.Sprintf("bad request"))
+		logger.ErrorContext(r.Context(), "bad request")
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	err = app.userService.UpdateUserProfile(r.Header.Get("login"), &user)
+	err = app.userService.RequestPasswordReset(r.Context(), &request)
+	if err != nil {
+		writeTokenError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (app *App) ConfirmPasswordReset(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /password-reset/confirm")
+
+	confirm := repository.PasswordResetConfirm{}
+	data, _ := io.ReadAll(r.Body)
+	err := json.Unmarshal(data, &confirm)
 	if err != nil {
-		var updateCredsErr *customError.UpdateCredentialsError
+		logger.ErrorContext(r.Context(), "bad request")
 		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
 
-		if errors.As(err, &updateCredsErr) {
-			_, _ = fmt.Fprint(w, err.Error())
-		}
+	err = app.userService.ConfirmPasswordReset(r.Context(), &confirm)
+	if err != nil {
+		writeTokenError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
 }
+
+func writeTokenError(w http.ResponseWriter, err error) {
+	var validationErr *customError.ValidationError
+	var invalidTokenErr *customError.InvalidTokenError
+
+	switch {
+	case errors.As(err, &validationErr):
+		writeValidationError(w, validationErr)
+	case errors.As(err, &invalidTokenErr):
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = fmt.Fprint(w, err.Error())
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
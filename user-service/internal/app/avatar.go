@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"social-network/pkg/logger"
+	customError "social-network/user-service/internal/errors"
+	"social-network/user-service/internal/storage"
+)
+
+// multipartOverhead leaves room for the multipart boundaries and headers around the file.
+const multipartOverhead = 64 << 10
+
+func (app *App) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "POST /user-profile/avatar")
+
+	r.Body = http.MaxBytesReader(w, r.Body, app.avatarMaxSize+multipartOverhead)
+	file, _, err := r.FormFile("avatar")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		logger.ErrorContext(r.Context(), "bad request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	avatar, err := app.userService.UploadAvatar(r.Context(), caller(r).Login, file)
+	if err != nil {
+		writeAvatarError(r.Context(), w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"avatar": avatar})
+}
+
+func (app *App) DeleteAvatar(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "DELETE /user-profile/avatar")
+
+	err := app.userService.DeleteAvatar(r.Context(), caller(r).Login)
+	if err != nil {
+		writeAvatarError(r.Context(), w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *App) GetAvatar(w http.ResponseWriter, r *http.Request) {
+	file, err := app.userService.OpenAvatar(r.URL.Path)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			logger.ErrorContext(r.Context(), "failed to open avatar", "path", r.URL.Path, "error", err)
+		}
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+
+	// avatar keys are never reused, so the file can be cached for long
+	w.Header().Set("Content-Type", mime.TypeByExtension(path.Ext(r.URL.Path)))
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	_, _ = io.Copy(w, file)
+}
+
+func writeAvatarError(ctx context.Context, w http.ResponseWriter, err error) {
+	var validationErr *customError.ValidationError
+	if errors.As(err, &validationErr) {
+		writeValidationError(w, validationErr)
+		return
+	}
+	var notFoundErr *customError.NotFoundUserError
+	if errors.As(err, &notFoundErr) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	logger.ErrorContext(ctx, "failed to update avatar", "error", err)
+	w.WriteHeader(http.StatusInternalServerError)
+}
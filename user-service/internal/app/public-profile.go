@@ -0,0 +1,69 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"social-network/pkg/logger"
+	customError "social-network/user-service/internal/errors"
+	"social-network/user-service/internal/repository"
+	"strconv"
+	"strings"
+)
+
+// GetPublicProfile serves /users/{id} and /users/by-login/{login}.
+func (app *App) GetPublicProfile(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "GET "+r.URL.Path)
+
+	viewerId := caller(r).UserId
+	path := strings.TrimPrefix(r.URL.Path, "/users/")
+
+	var profile *repository.PublicProfile
+	var err error
+	if login, ok := strings.CutPrefix(path, "by-login/"); ok {
+		profile, err = app.userService.GetPublicProfileByLogin(r.Context(), login, viewerId)
+	} else {
+		id, convErr := strconv.Atoi(path)
+		if convErr != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		profile, err = app.userService.GetPublicProfileById(r.Context(), id, viewerId)
+	}
+
+	if err != nil {
+		var notFoundErr *customError.NotFoundUserError
+		if errors.As(err, &notFoundErr) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, err.Error())
+			return
+		}
+		logger.ErrorContext(r.Context(), "failed to get public profile", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(profile)
+}
+
+func (app *App) UpdatePrivacy(w http.ResponseWriter, r *http.Request) {
+	logger.InfoContext(r.Context(), "PUT /user-profile/privacy")
+
+	privacy := repository.Privacy{}
+	data, _ := io.ReadAll(r.Body)
+	err := json.Unmarshal(data, &privacy)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "bad request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = app.userService.UpdatePrivacy(r.Context(), caller(r).Login, &privacy)
+	if err != nil {
+		writeCredentialsChangeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
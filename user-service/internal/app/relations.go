@@ -0,0 +1,73 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"social-network/pkg/logger"
+	customError "social-network/user-service/internal/errors"
+	"social-network/user-service/internal/repository"
+)
+
+func (app *App) AddRelation(w http.ResponseWriter, r *http.Request, kind string) {
+	logger.InfoContext(r.Context(), "POST "+r.URL.Path)
+
+	request := repository.RelationRequest{}
+	data, _ := io.ReadAll(r.Body)
+	err := json.Unmarshal(data, &request)
+	if err != nil {
+		logger.ErrorContext(r.Context(), "bad request")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = app.userService.AddRelation(r.Context(), caller(r).Login, kind, &request)
+	if err != nil {
+		writeRelationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (app *App) RemoveRelation(w http.ResponseWriter, r *http.Request, kind string) {
+	logger.InfoContext(r.Context(), "DELETE "+r.URL.Path)
+
+	request := repository.RelationRequest{Login: r.URL.Query().Get("login")}
+	err := app.userService.RemoveRelation(r.Context(), caller(r).Login, kind, &request)
+	if err != nil {
+		writeRelationError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (app *App) ListRelations(w http.ResponseWriter, r *http.Request, kind string) {
+	logger.InfoContext(r.Context(), "GET "+r.URL.Path)
+
+	related, err := app.userService.ListRelations(r.Context(), caller(r).Login, kind)
+	if err != nil {
+		writeRelationError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(related)
+}
+
+func writeRelationError(w http.ResponseWriter, err error) {
+	var validationErr *customError.ValidationError
+	var notFoundErr *customError.NotFoundUserError
+
+	switch {
+	case errors.As(err, &validationErr):
+		writeValidationError(w, validationErr)
+		return
+	case errors.As(err, &notFoundErr):
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	_, _ = fmt.Fprint(w, err.Error())
+}
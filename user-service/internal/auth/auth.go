@@ -0,0 +1,23 @@
+package auth
+
+import "social-network/user-service/internal/repository"
+
+// RoleService is used by other services calling on their own behalf
+const RoleService = "service"
+
+// Caller is the user on whose behalf another service calls user-service.
+// Login is only set for users.
+type Caller struct {
+	UserId int
+	Login  string
+	Role   string
+}
+
+// CanManage tells whether the caller may read and change the full profile of the user.
+func (c Caller) CanManage(userId int) bool {
+	return c.UserId == userId || c.Role == repository.RoleAdmin || c.IsService()
+}
+
+func (c Caller) IsService() bool {
+	return c.Role == RoleService
+}
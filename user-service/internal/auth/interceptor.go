@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"social-network/pkg/internaltoken"
+	"social-network/pkg/logger"
+	"social-network/user-service/internal/config"
+	"social-network/user-service/internal/repository"
+)
+
+// Audience is the audience of the internal tokens user-service accepts.
+const Audience = "user-service"
+
+var knownRoles = map[string]bool{
+	repository.RoleUser:      true,
+	repository.RoleModerator: true,
+	repository.RoleAdmin:     true,
+	RoleService:              true,
+}
+
+type ctxKey struct{}
+
+func NewContext(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, ctxKey{}, caller)
+}
+
+// FromContext returns the caller the interceptor authenticated.
+func FromContext(ctx context.Context) (Caller, bool) {
+	caller, ok := ctx.Value(ctxKey{}).(Caller)
+	return caller, ok
+}
+
+func NewVerifier(cfg *config.Config) (*internaltoken.Verifier, error) {
+	return internaltoken.NewVerifier(cfg.InternalTokenKey, Audience)
+}
+
+// UnaryServerInterceptor authenticates every call by the internal token the
+// api-gateway signed for it, so only holders of the internal key can call
+// user-service at all and nobody can claim a user or a role through metadata.
+func UnaryServerInterceptor(verifier *internaltoken.Verifier) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		principal, err := verifier.VerifyIncoming(ctx)
+		if err != nil {
+			logger.WarnContext(ctx, "unauthenticated call", "method", info.FullMethod, "error", err)
+			return nil, status.Error(codes.Unauthenticated, "invalid internal token")
+		}
+		if !knownRoles[principal.Role] {
+			logger.WarnContext(ctx, "unknown role in internal token", "method", info.FullMethod, "role", principal.Role)
+			return nil, status.Error(codes.Unauthenticated, "invalid internal token")
+		}
+
+		return handler(NewContext(ctx, callerOf(principal)), req)
+	}
+}
+
+func callerOf(principal internaltoken.Principal) Caller {
+	return Caller{UserId: int(principal.UserId), Login: principal.Login, Role: principal.Role}
+}
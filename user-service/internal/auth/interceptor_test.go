@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"social-network/pkg/internaltoken"
+	"social-network/user-service/internal/repository"
+)
+
+const (
+	testKey  = "test-key"
+	tokenKey = "x-internal-token"
+)
+
+func sign(t *testing.T, key string, audience string, principal internaltoken.Principal) string {
+	t.Helper()
+	signer, err := internaltoken.NewSigner(key, "api-gateway", audience)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := signer.Sign(principal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	verifier, err := internaltoken.NewVerifier(testKey, Audience)
+	if err != nil {
+		t.Fatal(err)
+	}
+	interceptor := UnaryServerInterceptor(verifier)
+	user := sign(t, testKey, Audience, internaltoken.Principal{UserId: 7, Role: repository.RoleUser})
+
+	tests := []struct {
+		name     string
+		md       metadata.MD
+		want     Caller
+		wantCode codes.Code
+	}{
+		{
+			name: "user token",
+			md:   metadata.Pairs(tokenKey, user),
+			want: Caller{UserId: 7, Role: repository.RoleUser},
+		},
+		{
+			name: "service token",
+			md:   metadata.Pairs(tokenKey, sign(t, testKey, Audience, internaltoken.Principal{Role: RoleService})),
+			want: Caller{Role: RoleService},
+		},
+		{
+			name:     "bare user_id and role without a token",
+			md:       metadata.Pairs("user_id", "1", "role", RoleService),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "token for posts-service",
+			md:       metadata.Pairs(tokenKey, sign(t, testKey, "posts-service", internaltoken.Principal{Role: RoleService})),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "wrong key",
+			md:       metadata.Pairs(tokenKey, sign(t, "other-key", Audience, internaltoken.Principal{Role: RoleService})),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "two tokens",
+			md:       metadata.Pairs(tokenKey, user, tokenKey, user),
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "unknown role",
+			md:       metadata.Pairs(tokenKey, sign(t, testKey, Audience, internaltoken.Principal{UserId: 1, Role: "superuser"})),
+			wantCode: codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *Caller
+			handler := func(ctx context.Context, req any) (any, error) {
+				caller, ok := FromContext(ctx)
+				if !ok {
+					t.Fatal("no caller in the handler context")
+				}
+				got = &caller
+				return nil, nil
+			}
+
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/UserService/GetUser"}, handler)
+			if tt.wantCode != codes.OK {
+				if status.Code(err) != tt.wantCode {
+					t.Fatalf("error = %v, want code %v", err, tt.wantCode)
+				}
+				if got != nil {
+					t.Fatal("handler was called")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if got == nil || *got != tt.want {
+				t.Errorf("caller = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"net/http"
+	"social-network/pkg/internaltoken"
+	"social-network/pkg/logger"
+)
+
+// Middleware authenticates the requests the api-gateway proxies by their
+// internal token, the same way UnaryServerInterceptor does calls. Besides the
+// roles of gRPC callers it lets through anonymous requests, such as password
+// resets, whose handlers don't act for a user.
+func Middleware(verifier *internaltoken.Verifier, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := verifier.VerifyRequest(r)
+		if err != nil {
+			logger.WarnContext(r.Context(), "unauthenticated request", "method", r.Method, "path", r.URL.Path, "error", err)
+			http.Error(w, "invalid internal token", http.StatusUnauthorized)
+			return
+		}
+		if !knownRoles[principal.Role] && principal.Role != internaltoken.RoleAnonymous {
+			logger.WarnContext(r.Context(), "unknown role in internal token", "method", r.Method, "path", r.URL.Path, "role", principal.Role)
+			http.Error(w, "invalid internal token", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), callerOf(principal))))
+	})
+}
@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"social-network/pkg/internaltoken"
+	"social-network/user-service/internal/repository"
+)
+
+func TestMiddleware(t *testing.T) {
+	verifier, err := internaltoken.NewVerifier(testKey, Audience)
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin := sign(t, testKey, Audience, internaltoken.Principal{UserId: 1, Login: "root", Role: repository.RoleAdmin})
+
+	tests := []struct {
+		name       string
+		headers    map[string][]string
+		want       Caller
+		wantStatus int
+	}{
+		{
+			name:       "user token",
+			headers:    map[string][]string{internaltoken.Header: {admin}},
+			want:       Caller{UserId: 1, Login: "root", Role: repository.RoleAdmin},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "anonymous token",
+			headers:    map[string][]string{internaltoken.Header: {sign(t, testKey, Audience, internaltoken.Principal{Role: internaltoken.RoleAnonymous})}},
+			want:       Caller{Role: internaltoken.RoleAnonymous},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "plain headers next to the token are ignored",
+			headers:    map[string][]string{internaltoken.Header: {sign(t, testKey, Audience, internaltoken.Principal{UserId: 7, Login: "alice", Role: repository.RoleUser})}, "Login": {"root"}, "Role": {"admin"}},
+			want:       Caller{UserId: 7, Login: "alice", Role: repository.RoleUser},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "bare login and role without a token",
+			headers:    map[string][]string{"Login": {"root"}, "Role": {"admin"}},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "token for posts-service",
+			headers:    map[string][]string{internaltoken.Header: {sign(t, testKey, "posts-service", internaltoken.Principal{UserId: 1, Login: "root", Role: repository.RoleAdmin})}},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "wrong key",
+			headers:    map[string][]string{internaltoken.Header: {sign(t, "other-key", Audience, internaltoken.Principal{UserId: 1, Login: "root", Role: repository.RoleAdmin})}},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "two tokens",
+			headers:    map[string][]string{internaltoken.Header: {admin, admin}},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "unknown role",
+			headers:    map[string][]string{internaltoken.Header: {sign(t, testKey, Audience, internaltoken.Principal{UserId: 1, Role: "superuser"})}},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *Caller
+			handler := Middleware(verifier, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				caller, ok := FromContext(r.Context())
+				if !ok {
+					t.Fatal("no caller in the request context")
+				}
+				got = &caller
+			}))
+
+			r := httptest.NewRequest(http.MethodPost, "/admin/users/suspend", nil)
+			for name, values := range tt.headers {
+				for _, value := range values {
+					r.Header.Add(name, value)
+				}
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				if got != nil {
+					t.Fatal("handler was called")
+				}
+				return
+			}
+			if got == nil || *got != tt.want {
+				t.Errorf("caller = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	"social-network/pkg/certs"
+	"social-network/pkg/internaltoken"
+	"social-network/pkg/logger"
+	"social-network/pkg/metrics"
+	"social-network/pkg/requestid"
+	pb "social-network/protos"
+	"social-network/user-service/internal/config"
+)
+
+func NewPostsClient(lc fx.Lifecycle, cfg *config.Config, internal *certs.Reloader) (pb.PostsServiceClient, error) {
+	signer, err := internaltoken.NewSigner(cfg.InternalTokenKey, "user-service", "posts-service")
+	if err != nil {
+		logger.Error("error creating internal token signer", "error", err)
+		return nil, err
+	}
+
+	conn, err := grpc.NewClient(
+		cfg.PostsGrpcAddr,
+		grpc.WithTransportCredentials(certs.ClientCredentials(internal)),
+		grpc.WithChainUnaryInterceptor(
+			metrics.UnaryClientInterceptor(),
+			requestid.UnaryClientInterceptor(),
+			signer.UnaryClientInterceptor(),
+		),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
+	if err != nil {
+		logger.Error("error connecting to posts service", "error", err)
+		return nil, err
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(_ context.Context) error {
+			return conn.Close()
+		},
+	})
+	return pb.NewPostsServiceClient(conn), nil
+}
+
+// ServiceContext calls posts-comments-service on behalf of user-service itself.
+func ServiceContext(ctx context.Context) context.Context {
+	return internaltoken.NewContext(ctx, internaltoken.Principal{Role: "service"})
+}
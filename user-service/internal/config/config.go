@@ -1,19 +1,135 @@
 package config
 
+import (
+	"os"
+	"social-network/pkg/certs"
+	"social-network/pkg/env"
+	"social-network/pkg/postgres"
+	"time"
+)
+
 type Config struct {
-	ServerAddr       string
-	PostgresDb       string
-	PostgresUser     string
-	PostgresPassword string
-	PostgresPort     int
+	ServerAddr string
+	GrpcAddr   string
+
+	Postgres postgres.Config
+
+	// InternalTLS is the certificate of the service and the CA its peers are
+	// verified with, calls between the services are mutual TLS once it is set
+	InternalTLS certs.Config
+	// InternalTokenKey signs the tokens posts-service authenticates user-service by
+	// and verifies the ones the api-gateway calls user-service with
+	InternalTokenKey string
+
+	// AppBaseURL is the web frontend the emailed links open. Its /verify-email
+	// page posts the token of the link to POST /verify-email, and its
+	// /reset-password page asks for a new password and posts it with the token
+	// to POST /password-reset/confirm. The API takes the tokens in request
+	// bodies only, so the links never point at it
+	AppBaseURL           string
+	EmailVerificationTTL time.Duration
+	PasswordResetTTL     time.Duration
+
+	LoginFreeAttempts int
+	LoginBaseLockout  time.Duration
+	LoginMaxLockout   time.Duration
+	IPFreeAttempts    int
+	IPBaseDelay       time.Duration
+	IPMaxDelay        time.Duration
+	// IPFailureDecay is how long it takes an IP address to be forgiven one failed login
+	IPFailureDecay time.Duration
+
+	MailSender   string
+	MailFrom     string
+	MailLogPath  string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUser     string
+	SMTPPassword string
+
+	PostsGrpcAddr string
+
+	// ReadinessTimeout bounds the checks of the dependencies behind /readyz
+	ReadinessTimeout time.Duration
+
+	StorageDir    string
+	AvatarMaxSize int64
+
+	// AccountDeletionGrace is how long a deleted account can still be restored
+	AccountDeletionGrace time.Duration
+	PurgeInterval        time.Duration
+	OutboxInterval       time.Duration
+	OutboxBatchSize      int
+	// a failed event is retried after OutboxRetryBaseDelay, doubled after every
+	// further failure up to OutboxRetryMaxDelay
+	OutboxRetryBaseDelay time.Duration
+	OutboxRetryMaxDelay  time.Duration
+	// OutboxMaxAttempts is how many times an event is tried before it is parked.
+	// With the delays above that is about four days, so an outage of the
+	// receiving service doesn't lose the event
+	OutboxMaxAttempts int
 }
 
 func NewConfig() *Config {
 	return &Config{
-		ServerAddr:       ":8081",
-		PostgresUser:     "user",
-		PostgresPassword: "password",
-		PostgresPort:     5432,
-		PostgresDb:       "users-db",
+		ServerAddr: ":8081",
+		GrpcAddr:   ":50052",
+
+		Postgres: postgres.Config{
+			Host:     "user-postgres",
+			Port:     5432,
+			Db:       "users-db",
+			User:     "user",
+			Password: "password",
+
+			ConnectAttempts: env.Int("DB_CONNECT_ATTEMPTS", 8),
+			ConnectBackoff:  500 * time.Millisecond,
+			MaxBackoff:      5 * time.Second,
+
+			TraceQueries: true,
+		},
+
+		InternalTLS:      certs.NewConfig("INTERNAL_TLS"),
+		InternalTokenKey: env.Secret("INTERNAL_TOKEN_KEY"),
+
+		AppBaseURL:           env.String("APP_BASE_URL", "http://localhost:3000"),
+		EmailVerificationTTL: 24 * time.Hour,
+		PasswordResetTTL:     time.Hour,
+
+		LoginFreeAttempts: 5,
+		LoginBaseLockout:  time.Minute,
+		LoginMaxLockout:   time.Hour,
+		IPFreeAttempts:    20,
+		IPBaseDelay:       time.Second,
+		IPMaxDelay:        15 * time.Minute,
+		IPFailureDecay:    time.Minute,
+
+		MailSender:   env.String("MAIL_SENDER", "log"),
+		MailFrom:     env.String("MAIL_FROM", "no-reply@social-network.local"),
+		MailLogPath:  os.Getenv("MAIL_LOG_PATH"),
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     env.Int("SMTP_PORT", 587),
+		SMTPUser:     os.Getenv("SMTP_USER"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+
+		PostsGrpcAddr: "posts-service:50051",
+
+		ReadinessTimeout: 2 * time.Second,
+
+		StorageDir:    env.String("STORAGE_DIR", "/var/lib/user-service/uploads"),
+		AvatarMaxSize: 2 << 20,
+
+		AccountDeletionGrace: 30 * 24 * time.Hour,
+		PurgeInterval:        time.Hour,
+		OutboxInterval:       10 * time.Second,
+		OutboxBatchSize:      50,
+		OutboxRetryBaseDelay: 10 * time.Second,
+		OutboxRetryMaxDelay:  time.Hour,
+		OutboxMaxAttempts:    100,
 	}
 }
+
+// NewInternalTLS supplies the config of the certificates of the service.
+func NewInternalTLS(cfg *Config) certs.Config {
+	return cfg.InternalTLS
+}
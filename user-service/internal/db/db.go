@@ -1,35 +1,56 @@
 package db
 
 import (
-	"context"
-	"database/sql"
-	"fmt"
-	_ "github.com/jackc/pgx/v5/stdlib"
-	"github.com/uptrace/bun"
-	"github.com/uptrace/bun/dialect/pgdialect"
+	"go.uber.org/fx"
+	"social-network/pkg/postgres"
 	"social-network/user-service/internal/config"
-	"social-network/user-service/internal/logger"
 	"social-network/user-service/internal/repository"
 )
 
-func InitDb(cfg *config.Config) *bun.DB {
-	dsn := fmt.Sprintf("postgres://%s:%s@user-postgres:%d/%s?sslmode=disable",
-		cfg.PostgresUser, cfg.PostgresPassword, cfg.PostgresPort, cfg.PostgresDb)
+// Module provides the *bun.DB of user-service with its schema applied.
+var Module = fx.Module("db",
+	postgres.Module,
+	fx.Provide(newConfig, newSchema),
+)
 
-	sqldb, err := sql.Open("pgx", dsn)
-	if err != nil {
-		logger.Error(fmt.Sprintf("init db err: %s", err.Error()))
-	}
+var models = []any{
+	(*repository.User)(nil),
+	(*repository.Token)(nil),
+	(*repository.AuditEntry)(nil),
+	(*repository.Relation)(nil),
+	(*repository.OutboxEvent)(nil),
+}
 
-	db := bun.NewDB(sqldb, pgdialect.New())
-	_, err = db.NewCreateTable().
-		IfNotExists().
-		Model((*repository.User)(nil)).
-		Exec(context.Background())
+// migrations add columns introduced after a table was first created,
+// CREATE TABLE IF NOT EXISTS leaves existing tables untouched
+var migrations = []string{
+	`ALTER TABLE "user" ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT FALSE`,
+	`ALTER TABLE "user" ADD COLUMN IF NOT EXISTS failed_logins BIGINT NOT NULL DEFAULT 0`,
+	`ALTER TABLE "user" ADD COLUMN IF NOT EXISTS locked_until TIMESTAMPTZ`,
+	`ALTER TABLE "user" ADD COLUMN IF NOT EXISTS role VARCHAR NOT NULL DEFAULT 'user'`,
+	`ALTER TABLE "user" ADD COLUMN IF NOT EXISTS suspended_at TIMESTAMPTZ`,
+	`ALTER TABLE "user" ADD COLUMN IF NOT EXISTS suspend_reason VARCHAR`,
+	`ALTER TABLE "user" ADD COLUMN IF NOT EXISTS token_version BIGINT NOT NULL DEFAULT 0`,
+	`ALTER TABLE "user" ADD COLUMN IF NOT EXISTS delete_after TIMESTAMPTZ`,
+	`ALTER TABLE "user" ADD COLUMN IF NOT EXISTS privacy JSONB NOT NULL
+		DEFAULT '{"name": true, "family_name": true, "registered_at": true, "posts_count": true}'`,
+	`ALTER TABLE "user" ADD COLUMN IF NOT EXISTS avatar VARCHAR`,
+	`ALTER TABLE "user" ADD COLUMN IF NOT EXISTS bio VARCHAR`,
+	`ALTER TABLE "user" ADD COLUMN IF NOT EXISTS birthday VARCHAR`,
+	`ALTER TABLE "user" ADD COLUMN IF NOT EXISTS interests TEXT[]`,
+	`ALTER TABLE "user" ADD COLUMN IF NOT EXISTS address VARCHAR`,
+	`ALTER TABLE "user" ADD COLUMN IF NOT EXISTS private_info VARCHAR`,
+	`ALTER TABLE outbox_event ADD COLUMN IF NOT EXISTS parked_at TIMESTAMPTZ`,
+	`ALTER TABLE outbox_event ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ`,
+	// users created before avatar and bio existed get the default privacy for them
+	`UPDATE "user" SET privacy = '{"avatar": true, "bio": true, "birthday": false, "interests": true}'::jsonb || privacy
+		WHERE NOT privacy ? 'avatar'`,
+}
+
+func newConfig(cfg *config.Config) postgres.Config {
+	return cfg.Postgres
+}
 
-	if err != nil {
-		logger.Error(fmt.Sprintf("create table err: %s", err.Error()))
-	}
-	logger.Info(fmt.Sprintf("init db success: %s", dsn))
-	return db
+func newSchema() postgres.Schema {
+	return postgres.Schema{Models: models, Migrations: migrations}
 }
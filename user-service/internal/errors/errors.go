@@ -1,5 +1,10 @@
 package errors
 
+import (
+	"social-network/pkg/validation"
+	"time"
+)
+
 type NotFoundUserError struct{}
 
 func (nf *NotFoundUserError) Error() string {
@@ -17,3 +22,49 @@ type UpdateCredentialsError struct{}
 func (usd *UpdateCredentialsError) Error() string {
 	return "You can't update credentials"
 }
+
+type WrongPasswordError struct{}
+
+func (wpe *WrongPasswordError) Error() string {
+	return "Current password is incorrect"
+}
+
+type UnknownFieldError struct {
+	Field string
+}
+
+func (ufe *UnknownFieldError) Error() string {
+	return "Unknown field: " + ufe.Field
+}
+
+type FieldError = validation.FieldError
+
+type ValidationError = validation.ValidationError
+
+type InvalidTokenError struct{}
+
+func (ite *InvalidTokenError) Error() string {
+	return "Token is invalid or expired"
+}
+
+type TooManyAttemptsError struct {
+	RetryAfter time.Duration
+}
+
+func (tma *TooManyAttemptsError) Error() string {
+	return "Too many login attempts, try again later"
+}
+
+type PermissionDeniedError struct{}
+
+func (pde *PermissionDeniedError) Error() string {
+	return "Not enough permissions"
+}
+
+type AccountSuspendedError struct {
+	Reason string
+}
+
+func (ase *AccountSuspendedError) Error() string {
+	return "Account is suspended: " + ase.Reason
+}
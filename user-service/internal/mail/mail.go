@@ -0,0 +1,101 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"os"
+	"social-network/pkg/logger"
+	"social-network/user-service/internal/config"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Sender interface {
+	Send(ctx context.Context, to string, subject string, body string) error
+}
+
+func NewSender(cfg *config.Config) Sender {
+	if cfg.MailSender == "smtp" {
+		return NewSMTPSender(cfg)
+	}
+	return NewLogSender(cfg)
+}
+
+type SMTPSender struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTPSender(cfg *config.Config) *SMTPSender {
+	var auth smtp.Auth
+	if cfg.SMTPUser != "" {
+		auth = smtp.PlainAuth("", cfg.SMTPUser, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+
+	return &SMTPSender{
+		addr: fmt.Sprintf("%s:%d", cfg.SMTPHost, cfg.SMTPPort),
+		from: cfg.MailFrom,
+		auth: auth,
+	}
+}
+
+func (ss *SMTPSender) Send(ctx context.Context, to string, subject string, body string) error {
+	err := smtp.SendMail(ss.addr, ss.auth, ss.from, []string{to}, buildMessage(ss.from, to, subject, body))
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to send mail", "error", err)
+		return err
+	}
+	return nil
+}
+
+// LogSender writes messages to a file, or to the service log when no file is set.
+// It is meant for local development and tests only.
+type LogSender struct {
+	mu   sync.Mutex
+	path string
+	from string
+}
+
+func NewLogSender(cfg *config.Config) *LogSender {
+	return &LogSender{
+		path: cfg.MailLogPath,
+		from: cfg.MailFrom,
+	}
+}
+
+func (ls *LogSender) Send(ctx context.Context, to string, subject string, body string) error {
+	if ls.path == "" {
+		logger.InfoContext(ctx, "mail", "to", to, "subject", subject, "body", body)
+		return nil
+	}
+
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	file, err := os.OpenFile(ls.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to open mail log", "error", err)
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(buildMessage(ls.from, to, subject, body), '\n'))
+	return err
+}
+
+func buildMessage(from string, to string, subject string, body string) []byte {
+	var sb strings.Builder
+	sb.WriteString("From: " + from + "\r\n")
+	sb.WriteString("To: " + to + "\r\n")
+	sb.WriteString("Subject: " + subject + "\r\n")
+	sb.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	sb.WriteString("\r\n")
+	sb.WriteString(body)
+	sb.WriteString("\r\n")
+	return []byte(sb.String())
+}
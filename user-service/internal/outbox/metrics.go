@@ -0,0 +1,20 @@
+package outbox
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// eventsFailed counts the failed deliveries by the kind of event.
+	eventsFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "outbox_delivery_failures_total",
+		Help: "Failed outbox event deliveries by kind.",
+	}, []string{"kind"})
+
+	// eventsParked is how many events have been parked and wait to be requeued.
+	eventsParked = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "outbox_events_parked",
+		Help: "Outbox events parked after too many failed deliveries.",
+	})
+)
@@ -0,0 +1,130 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"social-network/pkg/logger"
+	pb "social-network/protos"
+	"social-network/user-service/internal/client"
+	"social-network/user-service/internal/config"
+	"social-network/user-service/internal/repository"
+	"time"
+)
+
+const deliveryTimeout = 5 * time.Second
+
+// store is the part of the outbox repository the dispatcher works with.
+type store interface {
+	GetPendingEvents(ctx context.Context, limit int) ([]repository.OutboxEvent, error)
+	MarkSent(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, cause error, retryAt time.Time) error
+	Park(ctx context.Context, id int64, cause error) error
+	CountParked(ctx context.Context) (int, error)
+}
+
+// Dispatcher delivers outbox events to the services that act on them.
+// Delivery is at least once, so the receiving calls must be idempotent.
+type Dispatcher struct {
+	outboxRepository store
+	postsClient      pb.PostsServiceClient
+	batchSize        int
+	maxAttempts      int
+	retryBaseDelay   time.Duration
+	retryMaxDelay    time.Duration
+}
+
+func NewDispatcher(outboxRepository *repository.OutboxRepository, postsClient pb.PostsServiceClient, cfg *config.Config) *Dispatcher {
+	return &Dispatcher{
+		outboxRepository: outboxRepository,
+		postsClient:      postsClient,
+		batchSize:        cfg.OutboxBatchSize,
+		maxAttempts:      cfg.OutboxMaxAttempts,
+		retryBaseDelay:   cfg.OutboxRetryBaseDelay,
+		retryMaxDelay:    cfg.OutboxRetryMaxDelay,
+	}
+}
+
+// Dispatch sends the due events in order. An event that fails is retried
+// with exponential backoff without holding back the ones after it, and
+// parked once it has failed maxAttempts times. The error reports how many
+// events failed.
+func (d *Dispatcher) Dispatch(ctx context.Context) error {
+	d.reportParked(ctx)
+
+	events, err := d.outboxRepository.GetPendingEvents(ctx, d.batchSize)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, event := range events {
+		err = d.deliver(ctx, event)
+		if err != nil {
+			failed++
+			d.fail(ctx, event, err)
+			continue
+		}
+
+		err = d.outboxRepository.MarkSent(ctx, event.Id)
+		if err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to deliver %d of %d events", failed, len(events))
+	}
+	return nil
+}
+
+func (d *Dispatcher) fail(ctx context.Context, event repository.OutboxEvent, cause error) {
+	attempts := event.Attempts + 1
+	eventsFailed.WithLabelValues(event.Kind).Inc()
+	if attempts < d.maxAttempts {
+		retryAt := time.Now().Add(d.backoff(attempts))
+		logger.WarnContext(ctx, "failed to deliver event", "kind", event.Kind, "event_id", event.Id, "attempts", attempts, "retry_at", retryAt, "error", cause)
+		err := d.outboxRepository.MarkFailed(ctx, event.Id, cause, retryAt)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to record event failure", "event_id", event.Id, "error", err)
+		}
+		return
+	}
+
+	logger.ErrorContext(ctx, "parked event after too many failures", "kind", event.Kind, "event_id", event.Id, "attempts", attempts, "error", cause)
+	err := d.outboxRepository.Park(ctx, event.Id, cause)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to park event", "event_id", event.Id, "error", err)
+	}
+}
+
+// backoff is how long an event that has failed the given number of times waits.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.retryBaseDelay
+	for i := 1; i < attempts && delay < d.retryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, d.retryMaxDelay)
+}
+
+// reportParked exposes how many events wait for an operator, see alerts.yml.
+func (d *Dispatcher) reportParked(ctx context.Context) {
+	parked, err := d.outboxRepository.CountParked(ctx)
+	if err != nil {
+		return
+	}
+	eventsParked.Set(float64(parked))
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, event repository.OutboxEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	defer cancel()
+
+	switch event.Kind {
+	case repository.EventUserDeleted:
+		_, err := d.postsClient.DeleteUserContent(client.ServiceContext(ctx), &pb.DeleteUserContentRequest{
+			UserId: int32(event.UserId),
+		})
+		return err
+	default:
+		return fmt.Errorf("unknown event kind %q", event.Kind)
+	}
+}
@@ -0,0 +1,157 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"slices"
+	pb "social-network/protos"
+	"social-network/user-service/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// fakeStore keeps the events in memory and records what the dispatcher did with them.
+type fakeStore struct {
+	events      []repository.OutboxEvent
+	parkedCount int
+	sent        []int64
+	failed      map[int64]time.Time
+	parked      []int64
+}
+
+func (f *fakeStore) GetPendingEvents(_ context.Context, limit int) ([]repository.OutboxEvent, error) {
+	return f.events[:min(limit, len(f.events))], nil
+}
+
+func (f *fakeStore) MarkSent(_ context.Context, id int64) error {
+	f.sent = append(f.sent, id)
+	return nil
+}
+
+func (f *fakeStore) MarkFailed(_ context.Context, id int64, _ error, retryAt time.Time) error {
+	f.failed[id] = retryAt
+	return nil
+}
+
+func (f *fakeStore) Park(_ context.Context, id int64, _ error) error {
+	f.parked = append(f.parked, id)
+	return nil
+}
+
+func (f *fakeStore) CountParked(context.Context) (int, error) {
+	return f.parkedCount, nil
+}
+
+// fakePosts fails DeleteUserContent for the users in failing.
+type fakePosts struct {
+	pb.PostsServiceClient
+	failing []int32
+	deleted []int32
+}
+
+func (f *fakePosts) DeleteUserContent(_ context.Context, req *pb.DeleteUserContentRequest, _ ...grpc.CallOption) (*emptypb.Empty, error) {
+	if slices.Contains(f.failing, req.GetUserId()) {
+		return nil, errors.New("posts-service is unavailable")
+	}
+	f.deleted = append(f.deleted, req.GetUserId())
+	return &emptypb.Empty{}, nil
+}
+
+func TestDispatch(t *testing.T) {
+	store := &fakeStore{
+		events: []repository.OutboxEvent{
+			{Id: 1, Kind: repository.EventUserDeleted, UserId: 10},
+			{Id: 2, Kind: repository.EventUserDeleted, UserId: 20, Attempts: 2},
+			{Id: 3, Kind: repository.EventUserDeleted, UserId: 30},
+			{Id: 4, Kind: repository.EventUserDeleted, UserId: 20, Attempts: 4},
+			{Id: 5, Kind: "user.unknown", UserId: 40},
+		},
+		parkedCount: 7,
+		failed:      make(map[int64]time.Time),
+	}
+	posts := &fakePosts{failing: []int32{20}}
+	d := &Dispatcher{
+		outboxRepository: store,
+		postsClient:      posts,
+		batchSize:        10,
+		maxAttempts:      5,
+		retryBaseDelay:   10 * time.Second,
+		retryMaxDelay:    time.Hour,
+	}
+
+	start := time.Now()
+	err := d.Dispatch(context.Background())
+	if err == nil {
+		t.Fatal("expected an error for the failed events")
+	}
+
+	if !slices.Equal(posts.deleted, []int32{10, 30}) {
+		t.Errorf("deleted content of %v, want the events after a failure delivered too", posts.deleted)
+	}
+	if !slices.Equal(store.sent, []int64{1, 3}) {
+		t.Errorf("sent = %v, want [1 3]", store.sent)
+	}
+
+	// the third failure of event 2 waits 40s, the unknown kind is retried like any failure
+	if retryAt, ok := store.failed[2]; !ok || retryAt.Sub(start) < 40*time.Second || retryAt.Sub(start) > 41*time.Second {
+		t.Errorf("event 2 retry at %v, want in 40s", retryAt.Sub(start))
+	}
+	if _, ok := store.failed[5]; !ok {
+		t.Error("event of an unknown kind is not marked failed")
+	}
+	if !slices.Equal(store.parked, []int64{4}) {
+		t.Errorf("parked = %v, want the event that reached max attempts", store.parked)
+	}
+	if _, ok := store.failed[4]; ok {
+		t.Error("parked event is also scheduled for a retry")
+	}
+
+	if got := testutil.ToFloat64(eventsParked); got != 7 {
+		t.Errorf("parked gauge = %v, want 7", got)
+	}
+}
+
+func TestDispatchRespectsBatchSize(t *testing.T) {
+	store := &fakeStore{
+		events: []repository.OutboxEvent{
+			{Id: 1, Kind: repository.EventUserDeleted, UserId: 10},
+			{Id: 2, Kind: repository.EventUserDeleted, UserId: 20},
+		},
+		failed: make(map[int64]time.Time),
+	}
+	d := &Dispatcher{outboxRepository: store, postsClient: &fakePosts{}, batchSize: 1, maxAttempts: 5}
+
+	err := d.Dispatch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(store.sent, []int64{1}) {
+		t.Errorf("sent = %v, want only the first batch", store.sent)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := &Dispatcher{retryBaseDelay: 10 * time.Second, retryMaxDelay: time.Hour}
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: 10 * time.Second},
+		{attempts: 2, want: 20 * time.Second},
+		{attempts: 3, want: 40 * time.Second},
+		{attempts: 9, want: 2560 * time.Second},
+		{attempts: 10, want: time.Hour},
+		{attempts: 100, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := d.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
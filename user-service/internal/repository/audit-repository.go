@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"github.com/uptrace/bun"
+	"social-network/pkg/logger"
+	"time"
+)
+
+type AuditRepository struct {
+	db *bun.DB
+}
+
+func NewAuditRepository(db *bun.DB) *AuditRepository {
+	return &AuditRepository{
+		db: db,
+	}
+}
+
+func (ar *AuditRepository) Write(ctx context.Context, entry *AuditEntry) error {
+	entry.CreatedAt = time.Now()
+	_, err := ar.db.NewInsert().
+		Model(entry).
+		Exec(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to write audit log", "error", err)
+		return err
+	}
+
+	return nil
+}
@@ -8,13 +8,267 @@ import (
 type User struct {
 	bun.BaseModel `bun:"table:user,select:user"`
 
-	Id           int       `bun:"id,pk,autoincrement" json:"id"`
-	Name         string    `bun:"name" json:"name"`
-	FamilyName   string    `bun:"family_name" json:"family_name"`
-	Login        string    `bun:"login" json:"login"`
-	Email        string    `bun:"email" json:"email"`
-	Password     string    `bun:"password" json:"password"`
-	Phone        string    `bun:"phone" json:"phone"`
-	RegisteredAt time.Time `bun:"registered_at" json:"registered_at"`
-	UpdatedAt    time.Time `bun:"updated_at" json:"updated_at"`
+	Id            int       `bun:"id,pk,autoincrement" json:"id"`
+	Name          string    `bun:"name" json:"name" validate:"omitempty,max=64"`
+	FamilyName    string    `bun:"family_name" json:"family_name" validate:"omitempty,max=64"`
+	Login         string    `bun:"login" json:"login" validate:"required,min=3,max=32,login"`
+	Email         string    `bun:"email" json:"email" validate:"required,max=254,email"`
+	Password      string    `bun:"password" json:"password" validate:"required,min=8,max=72"`
+	Phone         string    `bun:"phone" json:"phone" validate:"omitempty,e164"`
+	Avatar        string    `bun:"avatar" json:"avatar"`
+	Bio           string    `bun:"bio" json:"bio" validate:"omitempty,max=500"`
+	Birthday      string    `bun:"birthday" json:"birthday" validate:"omitempty,birthday"`
+	Interests     []string  `bun:"interests,array" json:"interests" validate:"max=20,dive,min=1,max=50"`
+	Address       string    `bun:"address" json:"address" validate:"omitempty,max=200"`
+	PrivateInfo   string    `bun:"private_info" json:"private_info" validate:"omitempty,max=2000"`
+	EmailVerified bool      `bun:"email_verified,notnull" json:"email_verified"`
+	Role          string    `bun:"role,notnull" json:"role"`
+	FailedLogins  int       `bun:"failed_logins,notnull" json:"-"`
+	LockedUntil   time.Time `bun:"locked_until,nullzero" json:"-"`
+	SuspendedAt   time.Time `bun:"suspended_at,nullzero" json:"-"`
+	SuspendReason string    `bun:"suspend_reason" json:"-"`
+	TokenVersion  int       `bun:"token_version,notnull" json:"-"`
+	DeleteAfter   time.Time `bun:"delete_after,nullzero" json:"-"`
+	Privacy       Privacy   `bun:"privacy,type:jsonb,notnull" json:"privacy"`
+	RegisteredAt  time.Time `bun:"registered_at" json:"registered_at"`
+	UpdatedAt     time.Time `bun:"updated_at" json:"updated_at"`
+}
+
+// Privacy tells which fields of the public profile other users see,
+// the id and login are always public.
+// Address and private info are never public.
+type Privacy struct {
+	Name         bool `json:"name"`
+	FamilyName   bool `json:"family_name"`
+	Avatar       bool `json:"avatar"`
+	Bio          bool `json:"bio"`
+	Birthday     bool `json:"birthday"`
+	Interests    bool `json:"interests"`
+	RegisteredAt bool `json:"registered_at"`
+	PostsCount   bool `json:"posts_count"`
+}
+
+func DefaultPrivacy() Privacy {
+	return Privacy{
+		Name:         true,
+		FamilyName:   true,
+		Avatar:       true,
+		Bio:          true,
+		Interests:    true,
+		RegisteredAt: true,
+		PostsCount:   true,
+	}
+}
+
+// PublicProfile is what other users see, fields hidden by privacy settings are left out.
+type PublicProfile struct {
+	Id           int        `json:"id"`
+	Login        string     `json:"login"`
+	Name         string     `json:"name,omitempty"`
+	FamilyName   string     `json:"family_name,omitempty"`
+	Avatar       string     `json:"avatar,omitempty"`
+	Bio          string     `json:"bio,omitempty"`
+	Birthday     string     `json:"birthday,omitempty"`
+	Interests    []string   `json:"interests,omitempty"`
+	RegisteredAt *time.Time `json:"registered_at,omitempty"`
+	PostsCount   *int32     `json:"posts_count,omitempty"`
+}
+
+// Author is the part of the public profile shown next to the user's posts.
+type Author struct {
+	Id          int    `json:"id"`
+	Login       string `json:"login"`
+	DisplayName string `json:"display_name,omitempty"`
+	Avatar      string `json:"avatar,omitempty"`
+}
+
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+const (
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposePasswordReset     = "password_reset"
+)
+
+type Token struct {
+	bun.BaseModel `bun:"table:user_token,select:user_token"`
+
+	Id        int       `bun:"id,pk,autoincrement"`
+	UserId    int       `bun:"user_id,notnull"`
+	Purpose   string    `bun:"purpose,notnull"`
+	Hash      string    `bun:"hash,notnull,unique"`
+	Email     string    `bun:"email"`
+	ExpiresAt time.Time `bun:"expires_at,notnull"`
+	UsedAt    time.Time `bun:"used_at,nullzero"`
+	CreatedAt time.Time `bun:"created_at,notnull"`
+}
+
+type PasswordChange struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password" validate:"required,min=8,max=72"`
+}
+
+type EmailChange struct {
+	Password string `json:"password"`
+	Email    string `json:"email" validate:"required,max=254,email"`
+}
+
+type EmailVerification struct {
+	Token string `json:"token"`
+}
+
+type PasswordResetRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type PasswordResetConfirm struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password" validate:"required,min=8,max=72"`
+}
+
+type AdminUserRequest struct {
+	Login string `json:"login" validate:"required"`
+}
+
+type SuspendRequest struct {
+	Login  string `json:"login" validate:"required"`
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type UserSummary struct {
+	Id            int        `json:"id"`
+	Login         string     `json:"login"`
+	Email         string     `json:"email"`
+	Name          string     `json:"name"`
+	FamilyName    string     `json:"family_name"`
+	Role          string     `json:"role"`
+	EmailVerified bool       `json:"email_verified"`
+	RegisteredAt  time.Time  `json:"registered_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+	SuspendedAt   *time.Time `json:"suspended_at,omitempty"`
+	SuspendReason string     `json:"suspend_reason,omitempty"`
+}
+
+type UserPage struct {
+	Users []UserSummary `json:"users"`
+	Total int           `json:"total"`
+}
+
+// UserStatus is checked by the api-gateway on every authenticated request.
+type UserStatus struct {
+	Id            int    `json:"id"`
+	Role          string `json:"role"`
+	Suspended     bool   `json:"suspended"`
+	SuspendReason string `json:"suspend_reason,omitempty"`
+	TokenVersion  int    `json:"token_version"`
+}
+
+type RoleChange struct {
+	Login string `json:"login" validate:"required"`
+	Role  string `json:"role" validate:"required,oneof=user moderator admin"`
+}
+
+type AuditEntry struct {
+	bun.BaseModel `bun:"table:audit_log,select:audit_log"`
+
+	Id         int64     `bun:"id,pk,autoincrement"`
+	ActorLogin string    `bun:"actor_login"`
+	ActorRole  string    `bun:"actor_role"`
+	Action     string    `bun:"action"`
+	Target     string    `bun:"target"`
+	Details    string    `bun:"details"`
+	CreatedAt  time.Time `bun:"created_at"`
+}
+
+const (
+	RelationBlock = "block"
+	RelationMute  = "mute"
+)
+
+// Relation is a block or a mute of TargetId by UserId. A block hides posts
+// both ways, a mute only hides the target's posts from the user's feed.
+type Relation struct {
+	bun.BaseModel `bun:"table:user_relation"`
+
+	UserId    int       `bun:"user_id,pk"`
+	TargetId  int       `bun:"target_id,pk"`
+	Kind      string    `bun:"kind,pk"`
+	CreatedAt time.Time `bun:"created_at"`
+}
+
+type RelationRequest struct {
+	Login string `json:"login" validate:"required"`
+}
+
+type RelatedUser struct {
+	Id        int       `json:"id"`
+	Login     string    `json:"login"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AccountDeletionRequest struct {
+	Password string `json:"password"`
+}
+
+type AccountDeletion struct {
+	DeleteAfter time.Time `json:"delete_after"`
+}
+
+// UserExport is everything user-service keeps about a user, the password hash aside.
+type UserExport struct {
+	Id            int           `json:"id"`
+	Login         string        `json:"login"`
+	Email         string        `json:"email"`
+	Name          string        `json:"name"`
+	FamilyName    string        `json:"family_name"`
+	Phone         string        `json:"phone"`
+	Avatar        string        `json:"avatar"`
+	Bio           string        `json:"bio"`
+	Birthday      string        `json:"birthday"`
+	Interests     []string      `json:"interests"`
+	Address       string        `json:"address"`
+	PrivateInfo   string        `json:"private_info"`
+	Privacy       Privacy       `json:"privacy"`
+	EmailVerified bool          `json:"email_verified"`
+	Role          string        `json:"role"`
+	RegisteredAt  time.Time     `json:"registered_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+	DeleteAfter   *time.Time    `json:"delete_after,omitempty"`
+	Blocked       []RelatedUser `json:"blocked"`
+	Muted         []RelatedUser `json:"muted"`
+}
+
+const EventUserDeleted = "user.deleted"
+
+// OutboxEvent is written in the same transaction as the change it announces
+// and delivered to other services afterwards, until they accept it. A failed
+// event is held back until NextAttemptAt, longer after every failure. One
+// that keeps failing is parked and left for an operator to requeue.
+type OutboxEvent struct {
+	bun.BaseModel `bun:"table:outbox_event"`
+
+	Id        int64     `bun:"id,pk,autoincrement"`
+	Kind      string    `bun:"kind,notnull"`
+	UserId    int       `bun:"user_id,notnull"`
+	Attempts  int       `bun:"attempts,notnull"`
+	LastError string    `bun:"last_error"`
+	CreatedAt time.Time `bun:"created_at,notnull"`
+	SentAt    time.Time `bun:"sent_at,nullzero"`
+	ParkedAt  time.Time `bun:"parked_at,nullzero"`
+
+	NextAttemptAt time.Time `bun:"next_attempt_at,nullzero"`
+}
+
+// Requeued tells how many parked outbox events were put back in the queue.
+type Requeued struct {
+	Events int `json:"events"`
+}
+
+// Actor is the user performing a privileged action, as passed by the api-gateway.
+type Actor struct {
+	Login string
+	Role  string
 }
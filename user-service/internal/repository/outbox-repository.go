@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"github.com/uptrace/bun"
+	"social-network/pkg/logger"
+	"time"
+)
+
+type OutboxRepository struct {
+	db *bun.DB
+}
+
+func NewOutboxRepository(db *bun.DB) *OutboxRepository {
+	return &OutboxRepository{
+		db: db,
+	}
+}
+
+// GetPendingEvents returns undelivered events that are not parked and are due
+// for another attempt, oldest first.
+func (or *OutboxRepository) GetPendingEvents(ctx context.Context, limit int) ([]OutboxEvent, error) {
+	var events []OutboxEvent
+	err := or.db.NewSelect().
+		Model(&events).
+		Where("sent_at IS NULL").
+		Where("parked_at IS NULL").
+		Where("next_attempt_at IS NULL OR next_attempt_at <= ?", time.Now()).
+		Order("id").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to get pending events", "error", err)
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (or *OutboxRepository) MarkSent(ctx context.Context, id int64) error {
+	_, err := or.db.NewUpdate().
+		Model((*OutboxEvent)(nil)).
+		Set("sent_at = ?", time.Now()).
+		Set("attempts = attempts + 1").
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to mark event sent", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// MarkFailed records a failed attempt and holds the event back until retryAt.
+func (or *OutboxRepository) MarkFailed(ctx context.Context, id int64, cause error, retryAt time.Time) error {
+	_, err := or.db.NewUpdate().
+		Model((*OutboxEvent)(nil)).
+		Set("attempts = attempts + 1").
+		Set("last_error = ?", cause.Error()).
+		Set("next_attempt_at = ?", retryAt).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to mark event failed", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// Park records the last failure of the event and stops delivering it.
+func (or *OutboxRepository) Park(ctx context.Context, id int64, cause error) error {
+	_, err := or.db.NewUpdate().
+		Model((*OutboxEvent)(nil)).
+		Set("attempts = attempts + 1").
+		Set("last_error = ?", cause.Error()).
+		Set("parked_at = ?", time.Now()).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to park event", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (or *OutboxRepository) CountParked(ctx context.Context) (int, error) {
+	count, err := or.db.NewSelect().
+		Model((*OutboxEvent)(nil)).
+		Where("sent_at IS NULL").
+		Where("parked_at IS NOT NULL").
+		Count(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to count parked events", "error", err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// RequeueParked puts every parked event back in the queue with a fresh
+// attempt budget and returns how many there were.
+func (or *OutboxRepository) RequeueParked(ctx context.Context) (int, error) {
+	result, err := or.db.NewUpdate().
+		Model((*OutboxEvent)(nil)).
+		Set("parked_at = NULL").
+		Set("attempts = 0").
+		Set("next_attempt_at = NULL").
+		Where("sent_at IS NULL").
+		Where("parked_at IS NOT NULL").
+		Exec(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to requeue parked events", "error", err)
+		return 0, err
+	}
+
+	requeued, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(requeued), nil
+}
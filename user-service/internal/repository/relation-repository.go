@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"github.com/uptrace/bun"
+	"social-network/pkg/logger"
+	"time"
+)
+
+type RelationRepository struct {
+	db *bun.DB
+}
+
+func NewRelationRepository(db *bun.DB) *RelationRepository {
+	return &RelationRepository{
+		db: db,
+	}
+}
+
+// AddRelation does nothing when the relation already exists.
+func (rr *RelationRepository) AddRelation(ctx context.Context, userId int, targetId int, kind string) error {
+	relation := &Relation{
+		UserId:    userId,
+		TargetId:  targetId,
+		Kind:      kind,
+		CreatedAt: time.Now(),
+	}
+	_, err := rr.db.NewInsert().
+		Model(relation).
+		On("CONFLICT DO NOTHING").
+		Exec(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to add relation", "kind", kind, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (rr *RelationRepository) RemoveRelation(ctx context.Context, userId int, targetId int, kind string) error {
+	_, err := rr.db.NewDelete().
+		Model((*Relation)(nil)).
+		Where("user_id = ?", userId).
+		Where("target_id = ?", targetId).
+		Where("kind = ?", kind).
+		Exec(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to remove relation", "kind", kind, "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// ListRelated returns the users the user has blocked or muted, newest first.
+func (rr *RelationRepository) ListRelated(ctx context.Context, userId int, kind string) ([]RelatedUser, error) {
+	related := make([]RelatedUser, 0)
+	err := rr.db.NewSelect().
+		TableExpr("user_relation AS r").
+		ColumnExpr("u.id, u.login, r.created_at").
+		Join(`JOIN "user" AS u ON u.id = r.target_id`).
+		Where("r.user_id = ?", userId).
+		Where("r.kind = ?", kind).
+		Order("r.created_at DESC").
+		Scan(ctx, &related)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to list relations", "kind", kind, "error", err)
+		return nil, err
+	}
+
+	return related, nil
+}
+
+// HiddenAuthorIds returns the users whose posts the user must not see:
+// those the user blocked or muted and those who blocked the user. With
+// blocksOnly the muted users are left out.
+func (rr *RelationRepository) HiddenAuthorIds(ctx context.Context, userId int, blocksOnly bool) ([]int, error) {
+	query := rr.db.NewSelect().
+		Model((*Relation)(nil)).
+		ColumnExpr("target_id").
+		Where("user_id = ?", userId)
+	if blocksOnly {
+		query = query.Where("kind = ?", RelationBlock)
+	}
+
+	ids := make([]int, 0)
+	err := query.
+		UnionAll(rr.db.NewSelect().
+			Model((*Relation)(nil)).
+			ColumnExpr("user_id").
+			Where("target_id = ?", userId).
+			Where("kind = ?", RelationBlock)).
+		Scan(ctx, &ids)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to get hidden authors", "error", err)
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// IsBlocked tells whether either user has blocked the other.
+func (rr *RelationRepository) IsBlocked(ctx context.Context, userId int, otherId int) (bool, error) {
+	exists, err := rr.db.NewSelect().
+		Model((*Relation)(nil)).
+		Where("kind = ?", RelationBlock).
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("user_id = ? AND target_id = ?", userId, otherId).
+				WhereOr("user_id = ? AND target_id = ?", otherId, userId)
+		}).
+		Exists(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to check block", "error", err)
+		return false, err
+	}
+
+	return exists, nil
+}
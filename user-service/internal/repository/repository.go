@@ -4,13 +4,15 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"fmt"
 	"github.com/uptrace/bun"
+	"social-network/pkg/logger"
 	customErros "social-network/user-service/internal/errors"
-	"social-network/user-service/internal/logger"
+	"strings"
 	"time"
 )
 
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 type UserRepository struct {
 	db *bun.DB
 }
@@ -21,71 +23,282 @@ func NewUserRepository(db *bun.DB) *UserRepository {
 	}
 }
 
-func (ur *UserRepository) RegisterUser(user *User) error {
-	oldUser, _ := ur.GetUserByLogin(user.Login)
+func (ur *UserRepository) RegisterUser(ctx context.Context, user *User) error {
+	oldUser, _ := ur.GetUserByLogin(ctx, user.Login)
 	if oldUser != nil {
 		return &customErros.LoginAlreadyTakenError{}
 	}
 
 	user.UpdatedAt = time.Now()
 	user.RegisteredAt = time.Now()
+	user.EmailVerified = false
+	user.Role = RoleUser
+	user.Privacy = DefaultPrivacy()
 
 	_, err := ur.db.NewInsert().
 		Model(user).
-		Exec(context.Background())
+		Exec(ctx)
 	if err != nil {
-		logger.Error(fmt.Sprintf("failed to insert user: %v", err))
+		logger.ErrorContext(ctx, "failed to insert user", "error", err)
 		return err
 	}
 
 	return nil
 }
 
-func (ur *UserRepository) LoginUser(user *User) error {
+func (ur *UserRepository) GetUserByLogin(ctx context.Context, login string) (*User, error) {
+	user := &User{}
 	err := ur.db.NewSelect().
 		Model(user).
-		Where("login = ? and password = ?", user.Login, user.Password).
-		Scan(context.Background())
+		Where("login = ?", login).
+		Scan(ctx)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return &customErros.NotFoundUserError{}
+			return nil, &customErros.NotFoundUserError{}
 		}
-
-		logger.Error(fmt.Sprintf("failed to login user: %v", err))
-		return err
+		logger.ErrorContext(ctx, "failed to query user", "error", err)
+		return nil, err
 	}
 
-	return nil
+	return user, nil
 }
 
-func (ur *UserRepository) GetUserByLogin(login string) (*User, error) {
+func (ur *UserRepository) GetUserById(ctx context.Context, id int) (*User, error) {
 	user := &User{}
 	err := ur.db.NewSelect().
 		Model(user).
-		Where("login = ?", login).
-		Scan(context.Background())
+		Where("id = ?", id).
+		Scan(ctx)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, &customErros.NotFoundUserError{}
 		}
-		logger.Error(fmt.Sprintf("failed to query user: %v", err))
+		logger.ErrorContext(ctx, "failed to query user", "error", err)
 		return nil, err
 	}
 
 	return user, nil
 }
 
-func (ur *UserRepository) UpdateUserProfile(login string, user *User) error {
+func (ur *UserRepository) GetUsersByIds(ctx context.Context, ids []int) ([]User, error) {
+	users := make([]User, 0, len(ids))
+	err := ur.db.NewSelect().
+		Model(&users).
+		Where("id IN (?)", bun.In(ids)).
+		Scan(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to query users", "error", err)
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (ur *UserRepository) GetUsersByEmail(ctx context.Context, email string) ([]User, error) {
+	var users []User
+	err := ur.db.NewSelect().
+		Model(&users).
+		Where("lower(email) = lower(?)", email).
+		Scan(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to query users", "error", err)
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (ur *UserRepository) UpdateUserProfile(ctx context.Context, login string, user *User, columns []string) error {
 	user.UpdatedAt = time.Now()
 	_, err := ur.db.NewUpdate().
 		Model(user).
+		Column(append(columns, "updated_at")...).
 		Where("login = ?", login).
-		OmitZero().
-		Exec(context.Background())
+		Exec(ctx)
 	if err != nil {
-		logger.Error(fmt.Sprintf("failed to update user: %v", err))
+		logger.ErrorContext(ctx, "failed to update user", "error", err)
 		return err
 	}
 
 	return nil
 }
+
+func (ur *UserRepository) UpdateUserById(ctx context.Context, id int, user *User, columns []string) error {
+	user.UpdatedAt = time.Now()
+	_, err := ur.db.NewUpdate().
+		Model(user).
+		Column(append(columns, "updated_at")...).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to update user", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (ur *UserRepository) RecordFailedLogin(ctx context.Context, id int, lockedUntil time.Time) error {
+	_, err := ur.db.NewUpdate().
+		Model((*User)(nil)).
+		Set("failed_logins = failed_logins + 1").
+		Set("locked_until = ?", bun.NullTime{Time: lockedUntil}).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to record failed login", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (ur *UserRepository) ResetFailedLogins(ctx context.Context, id int) error {
+	_, err := ur.db.NewUpdate().
+		Model((*User)(nil)).
+		Set("failed_logins = 0").
+		Set("locked_until = NULL").
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to reset failed logins", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// SearchUsers looks the query up in logins and emails, an empty query matches everyone.
+func (ur *UserRepository) SearchUsers(ctx context.Context, query string, limit int, offset int) ([]User, int, error) {
+	var users []User
+	q := ur.db.NewSelect().
+		Model(&users).
+		Order("id").
+		Limit(limit).
+		Offset(offset)
+	if query != "" {
+		pattern := "%" + likeEscaper.Replace(query) + "%"
+		q = q.WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("login ILIKE ?", pattern).
+				WhereOr("email ILIKE ?", pattern)
+		})
+	}
+
+	total, err := q.ScanAndCount(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to search users", "error", err)
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// SetPassword replaces the password and revokes every token issued with the old one.
+func (ur *UserRepository) SetPassword(ctx context.Context, id int, password string) error {
+	_, err := ur.db.NewUpdate().
+		Model((*User)(nil)).
+		Set("password = ?", password).
+		Set("token_version = token_version + 1").
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to set password", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// ResetPassword is SetPassword that also lifts the lockout, the owner of the
+// account has proven themselves by the reset token.
+func (ur *UserRepository) ResetPassword(ctx context.Context, id int, password string) error {
+	_, err := ur.db.NewUpdate().
+		Model((*User)(nil)).
+		Set("password = ?", password).
+		Set("token_version = token_version + 1").
+		Set("failed_logins = 0").
+		Set("locked_until = NULL").
+		Set("updated_at = ?", time.Now()).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to reset password", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (ur *UserRepository) IncrementTokenVersion(ctx context.Context, id int) error {
+	_, err := ur.db.NewUpdate().
+		Model((*User)(nil)).
+		Set("token_version = token_version + 1").
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to increment token version", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func (ur *UserRepository) DeleteUser(ctx context.Context, id int) error {
+	return ur.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewDelete().
+			Model((*Token)(nil)).
+			Where("user_id = ?", id).
+			Exec(ctx)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to delete user tokens", "error", err)
+			return err
+		}
+
+		_, err = tx.NewDelete().
+			Model((*Relation)(nil)).
+			Where("user_id = ?", id).
+			WhereOr("target_id = ?", id).
+			Exec(ctx)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to delete user relations", "error", err)
+			return err
+		}
+
+		_, err = tx.NewDelete().
+			Model((*User)(nil)).
+			Where("id = ?", id).
+			Exec(ctx)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to delete user", "error", err)
+			return err
+		}
+
+		// posts-comments-service deletes the user's content when the event is delivered
+		_, err = tx.NewInsert().
+			Model(&OutboxEvent{Kind: EventUserDeleted, UserId: id, CreatedAt: time.Now()}).
+			Exec(ctx)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to write user deleted event", "error", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// GetUsersDueForDeletion returns users whose deletion grace period is over.
+func (ur *UserRepository) GetUsersDueForDeletion(ctx context.Context, now time.Time, limit int) ([]User, error) {
+	var users []User
+	err := ur.db.NewSelect().
+		Model(&users).
+		Where("delete_after <= ?", now).
+		Order("delete_after").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to get users due for deletion", "error", err)
+		return nil, err
+	}
+
+	return users, nil
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/uptrace/bun"
+	"social-network/pkg/logger"
+	customErros "social-network/user-service/internal/errors"
+	"time"
+)
+
+type TokenRepository struct {
+	db *bun.DB
+}
+
+func NewTokenRepository(db *bun.DB) *TokenRepository {
+	return &TokenRepository{
+		db: db,
+	}
+}
+
+// CreateToken stores a new token and revokes the unused tokens
+// issued earlier to the same user for the same purpose.
+func (tr *TokenRepository) CreateToken(ctx context.Context, token *Token) error {
+	return tr.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().
+			Model((*Token)(nil)).
+			Set("used_at = ?", time.Now()).
+			Where("user_id = ? and purpose = ? and used_at is null", token.UserId, token.Purpose).
+			Exec(ctx)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to revoke tokens", "error", err)
+			return err
+		}
+
+		token.CreatedAt = time.Now()
+		_, err = tx.NewInsert().
+			Model(token).
+			Exec(ctx)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to insert token", "error", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// ConsumeToken marks an unused, unexpired token as used and returns it.
+// A token can be consumed only once.
+func (tr *TokenRepository) ConsumeToken(ctx context.Context, hash string, purpose string) (*Token, error) {
+	token := &Token{}
+	now := time.Now()
+	err := tr.db.NewUpdate().
+		Model(token).
+		Set("used_at = ?", now).
+		Where("hash = ? and purpose = ? and used_at is null and expires_at > ?", hash, purpose, now).
+		Returning("*").
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &customErros.InvalidTokenError{}
+		}
+		logger.ErrorContext(ctx, "failed to consume token", "error", err)
+		return nil, err
+	}
+
+	return token, nil
+}
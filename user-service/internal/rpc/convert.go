@@ -0,0 +1,69 @@
+package rpc
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"slices"
+	pb "social-network/protos"
+	customError "social-network/user-service/internal/errors"
+	"social-network/user-service/internal/repository"
+	"social-network/user-service/internal/service"
+)
+
+var credentialFields = []string{"login", "password", "email"}
+
+func toPbUser(user *repository.User) *pb.User {
+	return &pb.User{
+		Id:            int32(user.Id),
+		Login:         user.Login,
+		Email:         user.Email,
+		Name:          user.Name,
+		FamilyName:    user.FamilyName,
+		Phone:         user.Phone,
+		Avatar:        user.Avatar,
+		Bio:           user.Bio,
+		Birthday:      user.Birthday,
+		Interests:     user.Interests,
+		Address:       user.Address,
+		PrivateInfo:   user.PrivateInfo,
+		EmailVerified: user.EmailVerified,
+		Role:          user.Role,
+		Privacy: &pb.Privacy{
+			Name:         user.Privacy.Name,
+			FamilyName:   user.Privacy.FamilyName,
+			Avatar:       user.Privacy.Avatar,
+			Bio:          user.Privacy.Bio,
+			Birthday:     user.Privacy.Birthday,
+			Interests:    user.Privacy.Interests,
+			RegisteredAt: user.Privacy.RegisteredAt,
+			PostsCount:   user.Privacy.PostsCount,
+		},
+		RegisteredAt: timestamppb.New(user.RegisteredAt),
+		UpdatedAt:    timestamppb.New(user.UpdatedAt),
+	}
+}
+
+// fromPbUser takes only the profile fields, everything else is managed by user-service itself.
+func fromPbUser(user *pb.User) *repository.User {
+	return &repository.User{
+		Name:        user.GetName(),
+		FamilyName:  user.GetFamilyName(),
+		Phone:       user.GetPhone(),
+		Bio:         user.GetBio(),
+		Birthday:    user.GetBirthday(),
+		Interests:   user.GetInterests(),
+		Address:     user.GetAddress(),
+		PrivateInfo: user.GetPrivateInfo(),
+	}
+}
+
+func checkProfileFields(paths []string) error {
+	for _, path := range paths {
+		if slices.Contains(credentialFields, path) {
+			return &customError.UpdateCredentialsError{}
+		}
+		if !slices.Contains(service.ProfileFields, path) {
+			return &customError.UnknownFieldError{Field: path}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package rpc
+
+import (
+	"errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"social-network/pkg/validation"
+	customError "social-network/user-service/internal/errors"
+)
+
+func toStatusError(err error) error {
+	var validationErr *customError.ValidationError
+	var notFoundErr *customError.NotFoundUserError
+	var loginTakenErr *customError.LoginAlreadyTakenError
+	var updateCredsErr *customError.UpdateCredentialsError
+	var unknownFieldErr *customError.UnknownFieldError
+	var tooManyAttemptsErr *customError.TooManyAttemptsError
+	var suspendedErr *customError.AccountSuspendedError
+	var permissionDeniedErr *customError.PermissionDeniedError
+
+	switch {
+	case errors.As(err, &validationErr):
+		return validation.StatusError(validationErr)
+	case errors.As(err, &notFoundErr):
+		return status.Error(codes.NotFound, notFoundErr.Error())
+	case errors.As(err, &loginTakenErr):
+		return status.Error(codes.AlreadyExists, loginTakenErr.Error())
+	case errors.As(err, &updateCredsErr), errors.As(err, &unknownFieldErr):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.As(err, &tooManyAttemptsErr):
+		return tooManyAttemptsStatusError(tooManyAttemptsErr)
+	case errors.As(err, &suspendedErr), errors.As(err, &permissionDeniedErr):
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return err
+}
+
+func tooManyAttemptsStatusError(err *customError.TooManyAttemptsError) error {
+	retryInfo := &errdetails.RetryInfo{RetryDelay: durationpb.New(err.RetryAfter)}
+
+	st, detailsErr := status.New(codes.ResourceExhausted, err.Error()).WithDetails(retryInfo)
+	if detailsErr != nil {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return st.Err()
+}
@@ -0,0 +1,223 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"social-network/pkg/logger"
+	pb "social-network/protos"
+	"social-network/user-service/internal/auth"
+	"social-network/user-service/internal/repository"
+	"social-network/user-service/internal/service"
+)
+
+// Server serves the gRPC API of user-service to the other services.
+type Server struct {
+	pb.UnimplementedUserServiceServer
+	userService service.UserServiceInterface
+}
+
+func NewServer(userService service.UserServiceInterface) *Server {
+	return &Server{
+		userService: userService,
+	}
+}
+
+func (s *Server) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.AuthToken, error) {
+	logger.InfoContext(ctx, "register called")
+	user := &repository.User{
+		Login:    req.GetLogin(),
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+	}
+
+	token, err := s.userService.Register(ctx, user)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.AuthToken{Token: token}, nil
+}
+
+func (s *Server) Login(ctx context.Context, req *pb.LoginRequest) (*pb.AuthToken, error) {
+	logger.InfoContext(ctx, "login called")
+	user := &repository.User{
+		Login:    req.GetLogin(),
+		Password: req.GetPassword(),
+	}
+
+	token, err := s.userService.Login(ctx, user, req.GetClientIp())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.AuthToken{Token: token}, nil
+}
+
+// GetUser returns the full profile, so only the user themselves, admins and other services may read it.
+func (s *Server) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.User, error) {
+	logger.InfoContext(ctx, "get user called")
+	caller, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var user *repository.User
+	switch key := req.GetKey().(type) {
+	case *pb.GetUserRequest_Id:
+		user, err = s.userService.GetUserById(ctx, int(key.Id))
+	case *pb.GetUserRequest_Login:
+		user, err = s.userService.GetUserProfile(ctx, key.Login)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "id or login is required")
+	}
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	if !caller.CanManage(user.Id) {
+		return nil, status.Error(codes.PermissionDenied, "Not enough permissions")
+	}
+	return toPbUser(user), nil
+}
+
+func (s *Server) BatchGetUsers(ctx context.Context, req *pb.BatchGetUsersRequest) (*pb.BatchGetUsersResponse, error) {
+	logger.InfoContext(ctx, "batch get users called")
+	_, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(req.GetIds()))
+	for i, id := range req.GetIds() {
+		ids[i] = int(id)
+	}
+
+	authors, err := s.userService.GetAuthors(ctx, ids)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	resp := &pb.BatchGetUsersResponse{}
+	for _, author := range authors {
+		resp.Users = append(resp.Users, &pb.UserSummary{
+			Id:          int32(author.Id),
+			Login:       author.Login,
+			DisplayName: author.DisplayName,
+			Avatar:      author.Avatar,
+		})
+	}
+	return resp, nil
+}
+
+// UpdateUser updates the profile fields listed in the mask, credentials are
+// changed through the dedicated HTTP endpoints that check the password.
+func (s *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.User, error) {
+	logger.InfoContext(ctx, "update user called")
+	caller, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !caller.CanManage(int(req.GetId())) {
+		return nil, status.Error(codes.PermissionDenied, "Not enough permissions")
+	}
+
+	current, err := s.userService.GetUserById(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	user := fromPbUser(req.GetUser())
+	if paths := req.GetUpdateMask().GetPaths(); len(paths) > 0 {
+		err = checkProfileFields(paths)
+		if err == nil {
+			err = s.userService.PatchUserProfile(ctx, current.Login, user, paths)
+		}
+	} else {
+		err = s.userService.UpdateUserProfile(ctx, current.Login, user)
+	}
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	updated, err := s.userService.GetUserById(ctx, current.Id)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toPbUser(updated), nil
+}
+
+// GetUserStatus is called by the api-gateway on every authenticated request.
+func (s *Server) GetUserStatus(ctx context.Context, req *pb.GetUserStatusRequest) (*pb.UserStatus, error) {
+	caller, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !caller.IsService() {
+		return nil, status.Error(codes.PermissionDenied, "Not enough permissions")
+	}
+
+	userStatus, err := s.userService.GetUserStatus(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return &pb.UserStatus{
+		Id:            int32(userStatus.Id),
+		Role:          userStatus.Role,
+		Suspended:     userStatus.Suspended,
+		SuspendReason: userStatus.SuspendReason,
+		TokenVersion:  int32(userStatus.TokenVersion),
+	}, nil
+}
+
+func (s *Server) GetHiddenAuthors(ctx context.Context, req *pb.GetHiddenAuthorsRequest) (*pb.GetHiddenAuthorsResponse, error) {
+	logger.InfoContext(ctx, "get hidden authors called")
+	caller, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !caller.CanManage(int(req.GetUserId())) {
+		return nil, status.Error(codes.PermissionDenied, "Not enough permissions")
+	}
+
+	ids, err := s.userService.GetHiddenAuthors(ctx, int(req.GetUserId()), req.GetBlocksOnly())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	resp := &pb.GetHiddenAuthorsResponse{Ids: make([]int32, len(ids))}
+	for i, id := range ids {
+		resp.Ids[i] = int32(id)
+	}
+	return resp, nil
+}
+
+func (s *Server) ExportUser(ctx context.Context, req *pb.ExportUserRequest) (*pb.UserExport, error) {
+	logger.InfoContext(ctx, "export user called")
+	caller, err := callerFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	export, err := s.userService.ExportUser(ctx, req.GetLogin())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	if !caller.CanManage(export.Id) {
+		return nil, status.Error(codes.PermissionDenied, "Not enough permissions")
+	}
+
+	document, err := json.Marshal(export)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.UserExport{Document: document}, nil
+}
+
+// callerFromContext returns the caller the internal token of the call names.
+func callerFromContext(ctx context.Context) (auth.Caller, error) {
+	caller, ok := auth.FromContext(ctx)
+	if !ok {
+		return auth.Caller{}, status.Error(codes.Unauthenticated, "invalid internal token")
+	}
+	return caller, nil
+}
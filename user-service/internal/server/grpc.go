@@ -0,0 +1,23 @@
+package server
+
+import (
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+	"social-network/pkg/certs"
+	"social-network/pkg/internaltoken"
+	"social-network/pkg/serving"
+	pb "social-network/protos"
+	"social-network/user-service/internal/auth"
+	"social-network/user-service/internal/config"
+	"social-network/user-service/internal/rpc"
+)
+
+// InvokeGrpcServer serves the gRPC API next to the HTTP server.
+func InvokeGrpcServer(lc fx.Lifecycle, cfg *config.Config, server *rpc.Server, internal *certs.Reloader, verifier *internaltoken.Verifier) {
+	grpcServer := serving.NewGRPCServer(
+		grpc.Creds(certs.ServerCredentials(internal)),
+		grpc.ChainUnaryInterceptor(auth.UnaryServerInterceptor(verifier)),
+	)
+	pb.RegisterUserServiceServer(grpcServer, server)
+	serving.GRPC(lc, cfg.GrpcAddr, grpcServer)
+}
@@ -1,50 +1,118 @@
 package server
 
 import (
-	"context"
-	"go.uber.org/fx"
+	"github.com/uptrace/bun"
 	"net/http"
+	"social-network/pkg/certs"
+	"social-network/pkg/health"
+	"social-network/pkg/internaltoken"
+	"social-network/pkg/metrics"
+	"social-network/pkg/postgres"
+	"social-network/pkg/requestid"
+	"social-network/pkg/tracing"
 	"social-network/user-service/internal/app"
+	"social-network/user-service/internal/auth"
 	"social-network/user-service/internal/config"
-	"social-network/user-service/internal/logger"
+	"social-network/user-service/internal/repository"
 )
 
-func NewServer(cfg *config.Config, app *app.App) *http.Server {
+func NewServer(cfg *config.Config, app *app.App, checker *health.Checker, internal *certs.Reloader, verifier *internaltoken.Verifier) *http.Server {
 	mux := http.NewServeMux()
-	mux.Handle("/register", http.HandlerFunc(app.Register))
-	mux.Handle("/login", http.HandlerFunc(app.Login))
+	// registration, login, profiles, statuses, hidden authors and exports are
+	// served over gRPC only, see the rpc package
 	mux.HandleFunc("/user-profile", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodDelete:
+			app.DeleteAccount(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.Handle("/user-profile/password", http.HandlerFunc(app.ChangePassword))
+	mux.Handle("/user-profile/email", http.HandlerFunc(app.ChangeEmail))
+	mux.Handle("/user-profile/privacy", http.HandlerFunc(app.UpdatePrivacy))
+	mux.Handle("/users/", http.HandlerFunc(app.GetPublicProfile))
+	mux.HandleFunc("/user-profile/avatar", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			app.UploadAvatar(w, r)
+		case http.MethodDelete:
+			app.DeleteAvatar(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	mux.Handle("/avatars/", http.HandlerFunc(app.GetAvatar))
+	mux.Handle("/user-profile/restore", http.HandlerFunc(app.RestoreAccount))
+	mux.HandleFunc("/user-profile/blocks", relationHandler(app, repository.RelationBlock))
+	mux.HandleFunc("/user-profile/mutes", relationHandler(app, repository.RelationMute))
+	mux.Handle("/verify-email", http.HandlerFunc(app.VerifyEmail))
+	mux.Handle("/verify-email/resend", http.HandlerFunc(app.ResendEmailVerification))
+	mux.Handle("/password-reset/request", http.HandlerFunc(app.RequestPasswordReset))
+	mux.Handle("/password-reset/confirm", http.HandlerFunc(app.ConfirmPasswordReset))
+	mux.Handle("/admin/unlock", http.HandlerFunc(app.UnlockUser))
+	mux.Handle("/admin/role", http.HandlerFunc(app.ChangeRole))
+	mux.HandleFunc("/admin/users", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
-			app.GetUserProfile(w, r)
-		case http.MethodPut:
-			app.UpdateUserProfile(w, r)
+			app.SearchUsers(w, r)
+		case http.MethodDelete:
+			app.DeleteUser(w, r)
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
 	})
+	mux.Handle("/admin/users/suspend", http.HandlerFunc(app.SuspendUser))
+	mux.Handle("/admin/users/unsuspend", http.HandlerFunc(app.UnsuspendUser))
+	mux.Handle("/admin/users/logout", http.HandlerFunc(app.ForceLogout))
+	mux.Handle("/admin/outbox/requeue", http.HandlerFunc(app.RequeueOutbox))
+	mux.Handle("/healthz", http.HandlerFunc(health.Live))
+	mux.Handle("/readyz", http.HandlerFunc(checker.Ready))
+	mux.Handle("/metrics", metrics.Handler())
+
+	// every request but the probes and scrapes is proxied by the api-gateway
+	// and has to carry an internal token
+	authenticated := auth.Middleware(verifier, mux)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if openPaths[r.URL.Path] {
+			mux.ServeHTTP(w, r)
+			return
+		}
+		authenticated.ServeHTTP(w, r)
+	})
 
 	return &http.Server{
-		Addr:    cfg.ServerAddr,
-		Handler: mux,
+		Addr:      cfg.ServerAddr,
+		Handler:   tracing.Middleware(mux, requestid.Middleware(metrics.Middleware(mux, handler))),
+		TLSConfig: certs.ServerTLS(internal),
 	}
 }
 
-func InvokeServer(lc fx.Lifecycle, srv *http.Server) error {
-	lc.Append(fx.Hook{
-		OnStart: func(_ context.Context) error {
-			go func() {
-				logger.Info("starting server on " + srv.Addr)
-				if err := srv.ListenAndServe(); err != nil {
-					logger.Error("error starting server: " + err.Error())
-				}
-			}()
-			return nil
-		},
-		OnStop: func(ctx context.Context) error {
-			return srv.Shutdown(ctx)
-		},
+var openPaths = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+// NewHealthChecker makes user-service ready once its database answers. Posts-comments-service
+// is left out, without it only posts counts and content cleanup are delayed.
+func NewHealthChecker(cfg *config.Config, db *bun.DB) *health.Checker {
+	return health.NewChecker(cfg.ReadinessTimeout, map[string]health.Check{
+		"database": postgres.Check(db),
 	})
+}
 
-	return nil
+func relationHandler(app *app.App, kind string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			app.ListRelations(w, r, kind)
+		case http.MethodPost:
+			app.AddRelation(w, r, kind)
+		case http.MethodDelete:
+			app.RemoveRelation(w, r, kind)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
 }
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"social-network/pkg/logger"
+	"social-network/user-service/internal/repository"
+	"time"
+)
+
+const purgeBatchSize = 100
+
+// systemActor is recorded in the audit log for actions nobody requested directly.
+var systemActor = repository.Actor{Login: "system", Role: "system"}
+
+// ScheduleDeletion deletes the account once the grace period is over,
+// until then the user can log in and cancel it.
+func (us *UserService) ScheduleDeletion(ctx context.Context, login string, request *repository.AccountDeletionRequest) (*repository.AccountDeletion, error) {
+	user, err := us.checkPassword(ctx, login, request.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.DeleteAfter.IsZero() {
+		user.DeleteAfter = time.Now().Add(us.cfg.AccountDeletionGrace)
+		err = us.userRepository.UpdateUserById(ctx, user.Id, user, []string{"delete_after"})
+		if err != nil {
+			return nil, err
+		}
+		us.audit(ctx, repository.Actor{Login: user.Login, Role: user.Role}, "user.delete_scheduled", user.Login, "")
+	}
+	return &repository.AccountDeletion{DeleteAfter: user.DeleteAfter}, nil
+}
+
+func (us *UserService) CancelDeletion(ctx context.Context, login string) error {
+	user, err := us.userRepository.GetUserByLogin(ctx, login)
+	if err != nil {
+		return err
+	}
+	if user.DeleteAfter.IsZero() {
+		return nil
+	}
+
+	user.DeleteAfter = time.Time{}
+	err = us.userRepository.UpdateUserById(ctx, user.Id, user, []string{"delete_after"})
+	if err != nil {
+		return err
+	}
+	us.audit(ctx, repository.Actor{Login: user.Login, Role: user.Role}, "user.delete_cancelled", user.Login, "")
+	return nil
+}
+
+// PurgeDueAccounts deletes the accounts whose grace period is over.
+func (us *UserService) PurgeDueAccounts(ctx context.Context) error {
+	for {
+		users, err := us.userRepository.GetUsersDueForDeletion(ctx, time.Now(), purgeBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, user := range users {
+			err = us.userRepository.DeleteUser(ctx, user.Id)
+			if err != nil {
+				return err
+			}
+			us.deleteAvatarFile(ctx, user.Avatar)
+			logger.InfoContext(ctx, "deleted account", "login", user.Login)
+			us.audit(ctx, systemActor, "user.delete", user.Login, fmt.Sprintf("id %d", user.Id))
+		}
+
+		if len(users) < purgeBatchSize {
+			return nil
+		}
+	}
+}
+
+func (us *UserService) ExportUser(ctx context.Context, login string) (*repository.UserExport, error) {
+	user, err := us.userRepository.GetUserByLogin(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	blocked, err := us.relationRepository.ListRelated(ctx, user.Id, repository.RelationBlock)
+	if err != nil {
+		return nil, err
+	}
+	muted, err := us.relationRepository.ListRelated(ctx, user.Id, repository.RelationMute)
+	if err != nil {
+		return nil, err
+	}
+
+	return &repository.UserExport{
+		Id:            user.Id,
+		Login:         user.Login,
+		Email:         user.Email,
+		Name:          user.Name,
+		FamilyName:    user.FamilyName,
+		Phone:         user.Phone,
+		Avatar:        user.Avatar,
+		Bio:           user.Bio,
+		Birthday:      user.Birthday,
+		Interests:     user.Interests,
+		Address:       user.Address,
+		PrivateInfo:   user.PrivateInfo,
+		Privacy:       user.Privacy,
+		EmailVerified: user.EmailVerified,
+		Role:          user.Role,
+		RegisteredAt:  user.RegisteredAt,
+		UpdatedAt:     user.UpdatedAt,
+		DeleteAfter:   timeOrNil(user.DeleteAfter),
+		Blocked:       blocked,
+		Muted:         muted,
+	}, nil
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"social-network/pkg/logger"
+	"social-network/pkg/validation"
+	customError "social-network/user-service/internal/errors"
+	"social-network/user-service/internal/repository"
+	"time"
+)
+
+func (us *UserService) UnlockUser(ctx context.Context, request *repository.AdminUserRequest, actor repository.Actor) error {
+	if actor.Role != repository.RoleAdmin {
+		return &customError.PermissionDeniedError{}
+	}
+
+	err := validation.Struct(request)
+	if err != nil {
+		return err
+	}
+
+	user, err := us.userRepository.GetUserByLogin(ctx, request.Login)
+	if err != nil {
+		return err
+	}
+
+	err = us.userRepository.ResetFailedLogins(ctx, user.Id)
+	if err != nil {
+		return err
+	}
+	us.audit(ctx, actor, "user.unlock", user.Login, "")
+	return nil
+}
+
+func (us *UserService) ChangeRole(ctx context.Context, change *repository.RoleChange, actor repository.Actor) error {
+	if actor.Role != repository.RoleAdmin {
+		return &customError.PermissionDeniedError{}
+	}
+
+	err := validation.Struct(change)
+	if err != nil {
+		return err
+	}
+
+	user, err := us.userRepository.GetUserByLogin(ctx, change.Login)
+	if err != nil {
+		return err
+	}
+
+	oldRole := user.Role
+	user.Role = change.Role
+	err = us.userRepository.UpdateUserById(ctx, user.Id, user, []string{"role"})
+	if err != nil {
+		return err
+	}
+	us.audit(ctx, actor, "user.role_change", user.Login, fmt.Sprintf("%s -> %s", oldRole, change.Role))
+	return nil
+}
+
+// RequeueParkedEvents puts the outbox events parked after too many failed
+// deliveries back in the queue, once what made them fail has been fixed.
+func (us *UserService) RequeueParkedEvents(ctx context.Context, actor repository.Actor) (*repository.Requeued, error) {
+	if actor.Role != repository.RoleAdmin {
+		return nil, &customError.PermissionDeniedError{}
+	}
+
+	requeued, err := us.outboxRepository.RequeueParked(ctx)
+	if err != nil {
+		return nil, err
+	}
+	us.audit(ctx, actor, "outbox.requeue", "", fmt.Sprintf("%d events", requeued))
+	return &repository.Requeued{Events: requeued}, nil
+}
+
+// audit records an action that has already been done, so it is written even
+// when the request is cancelled. An entry that cannot be written is logged in
+// full instead, it must not fail the action.
+func (us *UserService) audit(ctx context.Context, actor repository.Actor, action string, target string, details string) {
+	err := us.auditRepository.Write(context.WithoutCancel(ctx), &repository.AuditEntry{
+		ActorLogin: actor.Login,
+		ActorRole:  actor.Role,
+		Action:     action,
+		Target:     target,
+		Details:    details,
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "audit entry lost", "actor", actor.Login, "actor_role", actor.Role,
+			"action", action, "target", target, "details", details, "error", err)
+	}
+}
+
+func (us *UserService) SearchUsers(ctx context.Context, query string, limit int, offset int, actor repository.Actor) (*repository.UserPage, error) {
+	if actor.Role != repository.RoleAdmin {
+		return nil, &customError.PermissionDeniedError{}
+	}
+
+	users, total, err := us.userRepository.SearchUsers(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &repository.UserPage{
+		Users: make([]repository.UserSummary, 0, len(users)),
+		Total: total,
+	}
+	for _, user := range users {
+		page.Users = append(page.Users, repository.UserSummary{
+			Id:            user.Id,
+			Login:         user.Login,
+			Email:         user.Email,
+			Name:          user.Name,
+			FamilyName:    user.FamilyName,
+			Role:          user.Role,
+			EmailVerified: user.EmailVerified,
+			RegisteredAt:  user.RegisteredAt,
+			LockedUntil:   timeOrNil(user.LockedUntil),
+			SuspendedAt:   timeOrNil(user.SuspendedAt),
+			SuspendReason: user.SuspendReason,
+		})
+	}
+	return page, nil
+}
+
+func (us *UserService) SuspendUser(ctx context.Context, request *repository.SuspendRequest, actor repository.Actor) error {
+	if actor.Role != repository.RoleAdmin {
+		return &customError.PermissionDeniedError{}
+	}
+
+	err := validation.Struct(request)
+	if err != nil {
+		return err
+	}
+
+	user, err := us.userRepository.GetUserByLogin(ctx, request.Login)
+	if err != nil {
+		return err
+	}
+
+	user.SuspendedAt = time.Now()
+	user.SuspendReason = request.Reason
+	err = us.userRepository.UpdateUserById(ctx, user.Id, user, []string{"suspended_at", "suspend_reason"})
+	if err != nil {
+		return err
+	}
+	us.audit(ctx, actor, "user.suspend", user.Login, request.Reason)
+	return nil
+}
+
+func (us *UserService) UnsuspendUser(ctx context.Context, request *repository.AdminUserRequest, actor repository.Actor) error {
+	if actor.Role != repository.RoleAdmin {
+		return &customError.PermissionDeniedError{}
+	}
+
+	err := validation.Struct(request)
+	if err != nil {
+		return err
+	}
+
+	user, err := us.userRepository.GetUserByLogin(ctx, request.Login)
+	if err != nil {
+		return err
+	}
+
+	user.SuspendedAt = time.Time{}
+	user.SuspendReason = ""
+	err = us.userRepository.UpdateUserById(ctx, user.Id, user, []string{"suspended_at", "suspend_reason"})
+	if err != nil {
+		return err
+	}
+	us.audit(ctx, actor, "user.unsuspend", user.Login, "")
+	return nil
+}
+
+// ForceLogout revokes every token issued to the user so far.
+func (us *UserService) ForceLogout(ctx context.Context, request *repository.AdminUserRequest, actor repository.Actor) error {
+	if actor.Role != repository.RoleAdmin {
+		return &customError.PermissionDeniedError{}
+	}
+
+	err := validation.Struct(request)
+	if err != nil {
+		return err
+	}
+
+	user, err := us.userRepository.GetUserByLogin(ctx, request.Login)
+	if err != nil {
+		return err
+	}
+
+	err = us.userRepository.IncrementTokenVersion(ctx, user.Id)
+	if err != nil {
+		return err
+	}
+	us.audit(ctx, actor, "user.logout", user.Login, "")
+	return nil
+}
+
+func (us *UserService) DeleteUser(ctx context.Context, request *repository.AdminUserRequest, actor repository.Actor) error {
+	if actor.Role != repository.RoleAdmin {
+		return &customError.PermissionDeniedError{}
+	}
+
+	err := validation.Struct(request)
+	if err != nil {
+		return err
+	}
+
+	user, err := us.userRepository.GetUserByLogin(ctx, request.Login)
+	if err != nil {
+		return err
+	}
+
+	err = us.userRepository.DeleteUser(ctx, user.Id)
+	if err != nil {
+		return err
+	}
+	us.deleteAvatarFile(ctx, user.Avatar)
+	us.audit(ctx, actor, "user.delete", user.Login, fmt.Sprintf("id %d", user.Id))
+	return nil
+}
+
+func (us *UserService) GetUserStatus(ctx context.Context, id int) (*repository.UserStatus, error) {
+	user, err := us.userRepository.GetUserById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &repository.UserStatus{
+		Id:            user.Id,
+		Role:          user.Role,
+		Suspended:     !user.SuspendedAt.IsZero(),
+		SuspendReason: user.SuspendReason,
+		TokenVersion:  user.TokenVersion,
+	}, nil
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
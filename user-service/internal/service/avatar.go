@@ -0,0 +1,105 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net/http"
+	"social-network/pkg/logger"
+	customError "social-network/user-service/internal/errors"
+	"strings"
+)
+
+// avatarDir is both the storage key prefix and the public path avatars are served under.
+const avatarDir = "avatars/"
+
+var avatarExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// UploadAvatar stores the image under a new key, so cached copies of the old avatar never show up, and returns its path.
+func (us *UserService) UploadAvatar(ctx context.Context, login string, file io.Reader) (string, error) {
+	user, err := us.userRepository.GetUserByLogin(ctx, login)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, us.cfg.AvatarMaxSize+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(data)) > us.cfg.AvatarMaxSize {
+		return "", avatarError(fmt.Sprintf("must be at most %d KB", us.cfg.AvatarMaxSize>>10))
+	}
+	ext, ok := avatarExtensions[http.DetectContentType(data)]
+	if !ok {
+		return "", avatarError("must be a PNG, JPEG, GIF or WebP image")
+	}
+
+	key := fmt.Sprintf("%s%d-%s%s", avatarDir, user.Id, strings.ToLower(rand.Text()), ext)
+	err = us.storage.Save(ctx, key, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+
+	oldAvatar := user.Avatar
+	user.Avatar = "/" + key
+	err = us.userRepository.UpdateUserById(ctx, user.Id, user, []string{"avatar"})
+	if err != nil {
+		_ = us.storage.Delete(ctx, key)
+		return "", err
+	}
+
+	us.deleteAvatarFile(ctx, oldAvatar)
+	return user.Avatar, nil
+}
+
+func (us *UserService) DeleteAvatar(ctx context.Context, login string) error {
+	user, err := us.userRepository.GetUserByLogin(ctx, login)
+	if err != nil {
+		return err
+	}
+	if user.Avatar == "" {
+		return nil
+	}
+
+	oldAvatar := user.Avatar
+	user.Avatar = ""
+	err = us.userRepository.UpdateUserById(ctx, user.Id, user, []string{"avatar"})
+	if err != nil {
+		return err
+	}
+
+	us.deleteAvatarFile(ctx, oldAvatar)
+	return nil
+}
+
+// OpenAvatar opens the avatar served under the given path.
+func (us *UserService) OpenAvatar(path string) (io.ReadCloser, error) {
+	key := strings.TrimPrefix(path, "/")
+	if !strings.HasPrefix(key, avatarDir) {
+		return nil, fmt.Errorf("not an avatar path %q", path)
+	}
+	return us.storage.Open(key)
+}
+
+func (us *UserService) deleteAvatarFile(ctx context.Context, avatar string) {
+	if avatar == "" {
+		return
+	}
+	err := us.storage.Delete(ctx, strings.TrimPrefix(avatar, "/"))
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to delete avatar", "avatar", avatar, "error", err)
+	}
+}
+
+func avatarError(message string) error {
+	return &customError.ValidationError{Fields: []customError.FieldError{
+		{Field: "avatar", Message: message},
+	}}
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// usersRegistered counts the accounts created through registration.
+	usersRegistered = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "users_registered_total",
+		Help: "Users that completed registration.",
+	})
+
+	// loginsSucceeded counts the logins that issued a token.
+	loginsSucceeded = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "user_logins_succeeded_total",
+		Help: "Logins that issued a token.",
+	})
+
+	// loginsFailed counts the rejected logins by the reason they were rejected for.
+	loginsFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "user_logins_failed_total",
+		Help: "Rejected logins by reason.",
+	}, []string{"reason"})
+)
+
+// Reasons a login is rejected for, the values of the reason label of loginsFailed.
+const (
+	loginUnknownUser   = "unknown_user"
+	loginWrongPassword = "wrong_password"
+	loginThrottled     = "throttled"
+	loginLocked        = "locked"
+	loginSuspended     = "suspended"
+)
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"social-network/pkg/logger"
+	pb "social-network/protos"
+	"social-network/user-service/internal/client"
+	customError "social-network/user-service/internal/errors"
+	"social-network/user-service/internal/repository"
+	"strings"
+	"time"
+)
+
+const (
+	postsStatsTimeout = 2 * time.Second
+	maxAuthorsBatch   = 100
+)
+
+func (us *UserService) GetPublicProfileById(ctx context.Context, id int, viewerId int) (*repository.PublicProfile, error) {
+	user, err := us.userRepository.GetUserById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return us.publicProfile(ctx, user, viewerId)
+}
+
+func (us *UserService) GetPublicProfileByLogin(ctx context.Context, login string, viewerId int) (*repository.PublicProfile, error) {
+	user, err := us.userRepository.GetUserByLogin(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	return us.publicProfile(ctx, user, viewerId)
+}
+
+// GetAuthors returns the authors among the given users, suspended accounts and
+// accounts about to be deleted are left out as in public profiles.
+func (us *UserService) GetAuthors(ctx context.Context, ids []int) ([]repository.Author, error) {
+	if len(ids) > maxAuthorsBatch {
+		return nil, &customError.ValidationError{Fields: []customError.FieldError{
+			{Field: "ids", Message: fmt.Sprintf("must contain at most %d items", maxAuthorsBatch)},
+		}}
+	}
+
+	authors := make([]repository.Author, 0, len(ids))
+	if len(ids) == 0 {
+		return authors, nil
+	}
+
+	users, err := us.userRepository.GetUsersByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		if !user.SuspendedAt.IsZero() || !user.DeleteAfter.IsZero() {
+			continue
+		}
+
+		author := repository.Author{
+			Id:    user.Id,
+			Login: user.Login,
+		}
+		var names []string
+		if user.Privacy.Name && user.Name != "" {
+			names = append(names, user.Name)
+		}
+		if user.Privacy.FamilyName && user.FamilyName != "" {
+			names = append(names, user.FamilyName)
+		}
+		author.DisplayName = strings.Join(names, " ")
+		if user.Privacy.Avatar {
+			author.Avatar = user.Avatar
+		}
+		authors = append(authors, author)
+	}
+	return authors, nil
+}
+
+func (us *UserService) UpdatePrivacy(ctx context.Context, login string, privacy *repository.Privacy) error {
+	user, err := us.userRepository.GetUserByLogin(ctx, login)
+	if err != nil {
+		return err
+	}
+
+	user.Privacy = *privacy
+	return us.userRepository.UpdateUserById(ctx, user.Id, user, []string{"privacy"})
+}
+
+// publicProfile hides suspended accounts, accounts about to be deleted and
+// users blocked either way as if they did not exist.
+func (us *UserService) publicProfile(ctx context.Context, user *repository.User, viewerId int) (*repository.PublicProfile, error) {
+	if !user.SuspendedAt.IsZero() || !user.DeleteAfter.IsZero() {
+		return nil, &customError.NotFoundUserError{}
+	}
+
+	if viewerId != user.Id {
+		blocked, err := us.relationRepository.IsBlocked(ctx, viewerId, user.Id)
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			return nil, &customError.NotFoundUserError{}
+		}
+	}
+
+	profile := &repository.PublicProfile{
+		Id:    user.Id,
+		Login: user.Login,
+	}
+	if user.Privacy.Name {
+		profile.Name = user.Name
+	}
+	if user.Privacy.FamilyName {
+		profile.FamilyName = user.FamilyName
+	}
+	if user.Privacy.Avatar {
+		profile.Avatar = user.Avatar
+	}
+	if user.Privacy.Bio {
+		profile.Bio = user.Bio
+	}
+	if user.Privacy.Birthday {
+		profile.Birthday = user.Birthday
+	}
+	if user.Privacy.Interests {
+		profile.Interests = user.Interests
+	}
+	if user.Privacy.RegisteredAt {
+		profile.RegisteredAt = &user.RegisteredAt
+	}
+	if user.Privacy.PostsCount {
+		profile.PostsCount = us.postsCount(ctx, user.Id)
+	}
+	return profile, nil
+}
+
+// postsCount leaves the count out of the profile when posts-comments-service is unavailable.
+func (us *UserService) postsCount(ctx context.Context, userId int) *int32 {
+	ctx, cancel := context.WithTimeout(ctx, postsStatsTimeout)
+	defer cancel()
+
+	stats, err := us.postsClient.GetUserStats(client.ServiceContext(ctx), &pb.UserStatsRequest{UserId: int32(userId)})
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to get posts count", "user_id", userId, "error", err)
+		return nil
+	}
+	count := stats.GetPostsCount()
+	return &count
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"social-network/pkg/validation"
+	customError "social-network/user-service/internal/errors"
+	"social-network/user-service/internal/repository"
+)
+
+// AddRelation blocks or mutes the user from the request on behalf of login.
+func (us *UserService) AddRelation(ctx context.Context, login string, kind string, request *repository.RelationRequest) error {
+	user, target, err := us.relationUsers(ctx, login, request)
+	if err != nil {
+		return err
+	}
+	return us.relationRepository.AddRelation(ctx, user.Id, target.Id, kind)
+}
+
+func (us *UserService) RemoveRelation(ctx context.Context, login string, kind string, request *repository.RelationRequest) error {
+	user, target, err := us.relationUsers(ctx, login, request)
+	if err != nil {
+		return err
+	}
+	return us.relationRepository.RemoveRelation(ctx, user.Id, target.Id, kind)
+}
+
+func (us *UserService) ListRelations(ctx context.Context, login string, kind string) ([]repository.RelatedUser, error) {
+	user, err := us.userRepository.GetUserByLogin(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	return us.relationRepository.ListRelated(ctx, user.Id, kind)
+}
+
+// GetHiddenAuthors returns the ids of users whose posts the user must not see.
+// With blocksOnly it returns only the users blocked either way, whose posts stay
+// hidden even when opened directly, while mutes only apply to the feed.
+func (us *UserService) GetHiddenAuthors(ctx context.Context, id int, blocksOnly bool) ([]int, error) {
+	return us.relationRepository.HiddenAuthorIds(ctx, id, blocksOnly)
+}
+
+func (us *UserService) relationUsers(ctx context.Context, login string, request *repository.RelationRequest) (*repository.User, *repository.User, error) {
+	err := validation.Struct(request)
+	if err != nil {
+		return nil, nil, err
+	}
+	if request.Login == login {
+		return nil, nil, &customError.ValidationError{Fields: []customError.FieldError{
+			{Field: "login", Message: "login must not be your own"},
+		}}
+	}
+
+	user, err := us.userRepository.GetUserByLogin(ctx, login)
+	if err != nil {
+		return nil, nil, err
+	}
+	target, err := us.userRepository.GetUserByLogin(ctx, request.Login)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, target, nil
+}
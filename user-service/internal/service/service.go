@@ -1,77 +1,283 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/golang-jwt/jwt/v5"
+	"io"
 	"os"
+	"social-network/pkg/logger"
+	"social-network/pkg/throttle"
+	"social-network/pkg/validation"
+	pb "social-network/protos"
+	"social-network/user-service/internal/config"
 	customError "social-network/user-service/internal/errors"
-	"social-network/user-service/internal/logger"
+	"social-network/user-service/internal/mail"
 	"social-network/user-service/internal/repository"
+	"social-network/user-service/internal/storage"
+	"time"
 )
 
 type UserServiceInterface interface {
-	Register(user *repository.User) (string, error)
-	Login(user *repository.User) (string, error)
-	GetUserProfile(login string) (*repository.User, error)
-	UpdateUserProfile(login string, user *repository.User) error
+	Register(ctx context.Context, user *repository.User) (string, error)
+	Login(ctx context.Context, user *repository.User, ip string) (string, error)
+	UnlockUser(ctx context.Context, request *repository.AdminUserRequest, actor repository.Actor) error
+	ChangeRole(ctx context.Context, change *repository.RoleChange, actor repository.Actor) error
+	SearchUsers(ctx context.Context, query string, limit int, offset int, actor repository.Actor) (*repository.UserPage, error)
+	SuspendUser(ctx context.Context, request *repository.SuspendRequest, actor repository.Actor) error
+	UnsuspendUser(ctx context.Context, request *repository.AdminUserRequest, actor repository.Actor) error
+	ForceLogout(ctx context.Context, request *repository.AdminUserRequest, actor repository.Actor) error
+	DeleteUser(ctx context.Context, request *repository.AdminUserRequest, actor repository.Actor) error
+	RequeueParkedEvents(ctx context.Context, actor repository.Actor) (*repository.Requeued, error)
+	GetUserStatus(ctx context.Context, id int) (*repository.UserStatus, error)
+	GetUserProfile(ctx context.Context, login string) (*repository.User, error)
+	GetUserById(ctx context.Context, id int) (*repository.User, error)
+	UpdateUserProfile(ctx context.Context, login string, user *repository.User) error
+	PatchUserProfile(ctx context.Context, login string, user *repository.User, fields []string) error
+	ChangePassword(ctx context.Context, login string, change *repository.PasswordChange) error
+	ChangeEmail(ctx context.Context, login string, change *repository.EmailChange) error
+	VerifyEmail(ctx context.Context, verification *repository.EmailVerification) error
+	ResendEmailVerification(ctx context.Context, login string) error
+	RequestPasswordReset(ctx context.Context, request *repository.PasswordResetRequest) error
+	ConfirmPasswordReset(ctx context.Context, confirm *repository.PasswordResetConfirm) error
+	AddRelation(ctx context.Context, login string, kind string, request *repository.RelationRequest) error
+	RemoveRelation(ctx context.Context, login string, kind string, request *repository.RelationRequest) error
+	ListRelations(ctx context.Context, login string, kind string) ([]repository.RelatedUser, error)
+	GetHiddenAuthors(ctx context.Context, id int, blocksOnly bool) ([]int, error)
+	ScheduleDeletion(ctx context.Context, login string, request *repository.AccountDeletionRequest) (*repository.AccountDeletion, error)
+	CancelDeletion(ctx context.Context, login string) error
+	PurgeDueAccounts(ctx context.Context) error
+	ExportUser(ctx context.Context, login string) (*repository.UserExport, error)
+	GetPublicProfileById(ctx context.Context, id int, viewerId int) (*repository.PublicProfile, error)
+	GetPublicProfileByLogin(ctx context.Context, login string, viewerId int) (*repository.PublicProfile, error)
+	GetAuthors(ctx context.Context, ids []int) ([]repository.Author, error)
+	UpdatePrivacy(ctx context.Context, login string, privacy *repository.Privacy) error
+	UploadAvatar(ctx context.Context, login string, file io.Reader) (string, error)
+	DeleteAvatar(ctx context.Context, login string) error
+	OpenAvatar(path string) (io.ReadCloser, error)
 }
 
+var ProfileFields = []string{"name", "family_name", "phone", "bio", "birthday", "interests", "address", "private_info"}
+
 type UserService struct {
-	userRepository *repository.UserRepository
+	userRepository     *repository.UserRepository
+	tokenRepository    *repository.TokenRepository
+	auditRepository    *repository.AuditRepository
+	relationRepository *repository.RelationRepository
+	outboxRepository   *repository.OutboxRepository
+	mailSender         mail.Sender
+	storage            storage.Storage
+	postsClient        pb.PostsServiceClient
+	ipThrottle         *throttle.Throttle
+	cfg                *config.Config
 }
 
-func NewUserService(userRepository *repository.UserRepository) UserServiceInterface {
+func NewUserService(
+	userRepository *repository.UserRepository,
+	tokenRepository *repository.TokenRepository,
+	auditRepository *repository.AuditRepository,
+	relationRepository *repository.RelationRepository,
+	outboxRepository *repository.OutboxRepository,
+	mailSender mail.Sender,
+	storage storage.Storage,
+	postsClient pb.PostsServiceClient,
+	cfg *config.Config,
+) UserServiceInterface {
 	return &UserService{
-		userRepository: userRepository,
+		userRepository:     userRepository,
+		tokenRepository:    tokenRepository,
+		auditRepository:    auditRepository,
+		relationRepository: relationRepository,
+		outboxRepository:   outboxRepository,
+		mailSender:         mailSender,
+		storage:            storage,
+		postsClient:        postsClient,
+		ipThrottle:         throttle.New(cfg.IPFreeAttempts, cfg.IPBaseDelay, cfg.IPMaxDelay, cfg.IPFailureDecay),
+		cfg:                cfg,
 	}
 }
 
-func (us *UserService) Register(user *repository.User) (string, error) {
-	err := us.userRepository.RegisterUser(user)
+func (us *UserService) Register(ctx context.Context, user *repository.User) (string, error) {
+	err := validation.Struct(user)
+	if err != nil {
+		return "", err
+	}
+
+	err = us.userRepository.RegisterUser(ctx, user)
 	if err != nil {
 		return "", fmt.Errorf("failed to register user: %w", err)
 	}
+	usersRegistered.Inc()
+
+	err = us.sendEmailVerification(ctx, user)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to send email verification", "error", err)
+	}
 
-	token, err := createJWTToken(user)
+	token, err := createJWTToken(ctx, user)
 	if err != nil {
 		return "", fmt.Errorf("failed to create JWT token: %w", err)
 	}
 	return token, nil
 }
 
-func (us *UserService) Login(user *repository.User) (string, error) {
-	err := us.userRepository.LoginUser(user)
+func (us *UserService) Login(ctx context.Context, user *repository.User, ip string) (string, error) {
+	if retryAfter, ok := us.ipThrottle.Allow(ip); !ok {
+		loginsFailed.WithLabelValues(loginThrottled).Inc()
+		return "", &customError.TooManyAttemptsError{RetryAfter: retryAfter}
+	}
+
+	dbUser, err := us.userRepository.GetUserByLogin(ctx, user.Login)
 	if err != nil {
+		var notFoundErr *customError.NotFoundUserError
+		if errors.As(err, &notFoundErr) {
+			us.ipThrottle.Failure(ip)
+			loginsFailed.WithLabelValues(loginUnknownUser).Inc()
+		}
 		return "", fmt.Errorf("failed to login user: %w", err)
 	}
 
-	token, err := createJWTToken(user)
+	if retryAfter := time.Until(dbUser.LockedUntil); retryAfter > 0 {
+		loginsFailed.WithLabelValues(loginLocked).Inc()
+		return "", &customError.TooManyAttemptsError{RetryAfter: retryAfter}
+	}
+
+	if dbUser.Password != user.Password {
+		us.ipThrottle.Failure(ip)
+		loginsFailed.WithLabelValues(loginWrongPassword).Inc()
+
+		var lockedUntil time.Time
+		lockout := throttle.Delay(dbUser.FailedLogins+1, us.cfg.LoginFreeAttempts, us.cfg.LoginBaseLockout, us.cfg.LoginMaxLockout)
+		if lockout > 0 {
+			lockedUntil = time.Now().Add(lockout)
+			logger.InfoContext(ctx, "user locked", "login", dbUser.Login, "lockout", lockout)
+		}
+
+		// a caller hanging up right after a wrong guess must not escape the lockout
+		err = us.userRepository.RecordFailedLogin(context.WithoutCancel(ctx), dbUser.Id, lockedUntil)
+		if err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("failed to login user: %w", &customError.NotFoundUserError{})
+	}
+
+	if !dbUser.SuspendedAt.IsZero() {
+		loginsFailed.WithLabelValues(loginSuspended).Inc()
+		return "", &customError.AccountSuspendedError{Reason: dbUser.SuspendReason}
+	}
+	if dbUser.FailedLogins > 0 {
+		err = us.userRepository.ResetFailedLogins(ctx, dbUser.Id)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	token, err := createJWTToken(ctx, dbUser)
 	if err != nil {
 		return "", fmt.Errorf("failed to create JWT token: %w", err)
 	}
+	loginsSucceeded.Inc()
 	return token, nil
 }
 
-func (us *UserService) GetUserProfile(login string) (*repository.User, error) {
-	return us.userRepository.GetUserByLogin(login)
+func (us *UserService) GetUserProfile(ctx context.Context, login string) (*repository.User, error) {
+	return us.userRepository.GetUserByLogin(ctx, login)
 }
 
-func (us *UserService) UpdateUserProfile(login string, user *repository.User) error {
-	if user.Password != "" || user.Login != "" {
+func (us *UserService) GetUserById(ctx context.Context, id int) (*repository.User, error) {
+	return us.userRepository.GetUserById(ctx, id)
+}
+
+func (us *UserService) UpdateUserProfile(ctx context.Context, login string, user *repository.User) error {
+	if user.Password != "" || user.Login != "" || user.Email != "" {
 		return &customError.UpdateCredentialsError{}
 	}
-	return us.userRepository.UpdateUserProfile(login, user)
+
+	err := validation.StructPartial(user, ProfileFields...)
+	if err != nil {
+		return err
+	}
+	return us.userRepository.UpdateUserProfile(ctx, login, user, ProfileFields)
 }
 
-func createJWTToken(user *repository.User) (string, error) {
+func (us *UserService) PatchUserProfile(ctx context.Context, login string, user *repository.User, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	err := validation.StructPartial(user, fields...)
+	if err != nil {
+		return err
+	}
+	return us.userRepository.UpdateUserProfile(ctx, login, user, fields)
+}
+
+func (us *UserService) ChangePassword(ctx context.Context, login string, change *repository.PasswordChange) error {
+	err := validation.Struct(change)
+	if err != nil {
+		return err
+	}
+
+	user, err := us.checkPassword(ctx, login, change.CurrentPassword)
+	if err != nil {
+		return err
+	}
+	return us.userRepository.SetPassword(ctx, user.Id, change.NewPassword)
+}
+
+func (us *UserService) ChangeEmail(ctx context.Context, login string, change *repository.EmailChange) error {
+	err := validation.Struct(change)
+	if err != nil {
+		return err
+	}
+
+	user, err := us.checkPassword(ctx, login, change.Password)
+	if err != nil {
+		return err
+	}
+
+	user.Email = change.Email
+	user.EmailVerified = false
+	err = us.userRepository.UpdateUserProfile(ctx, login, user, []string{"email", "email_verified"})
+	if err != nil {
+		return err
+	}
+
+	err = us.sendEmailVerification(ctx, user)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to send email verification", "error", err)
+	}
+	return nil
+}
+
+func (us *UserService) checkPassword(ctx context.Context, login string, password string) (*repository.User, error) {
+	user, err := us.userRepository.GetUserByLogin(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	if user.Password != password {
+		return nil, &customError.WrongPasswordError{}
+	}
+	return user, nil
+}
+
+func createJWTToken(ctx context.Context, user *repository.User) (string, error) {
+	role := user.Role
+	if role == "" {
+		role = repository.RoleUser
+	}
+
 	claims := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"name":    user.Name,
-		"login":   user.Login,
-		"user-id": user.Id,
+		"name":          user.Name,
+		"login":         user.Login,
+		"user-id":       user.Id,
+		"role":          role,
+		"token-version": user.TokenVersion,
 	})
 	token, err := claims.SignedString([]byte(os.Getenv("SECRET_KEY")))
 	if err != nil {
-		logger.Error(fmt.Sprintf("error creating jtw token: %v", err))
+		logger.ErrorContext(ctx, "error creating jtw token", "error", err)
 		return "", err
 	}
 	return token, nil
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"social-network/pkg/logger"
+	"social-network/pkg/validation"
+	customError "social-network/user-service/internal/errors"
+	"social-network/user-service/internal/repository"
+	"time"
+)
+
+func (us *UserService) VerifyEmail(ctx context.Context, verification *repository.EmailVerification) error {
+	token, err := us.tokenRepository.ConsumeToken(ctx, hashToken(verification.Token), repository.TokenPurposeEmailVerification)
+	if err != nil {
+		return err
+	}
+
+	user, err := us.userRepository.GetUserById(ctx, token.UserId)
+	if err != nil {
+		return err
+	}
+	// the address was changed after the token had been issued
+	if user.Email != token.Email {
+		return &customError.InvalidTokenError{}
+	}
+
+	user.EmailVerified = true
+	return us.userRepository.UpdateUserById(ctx, user.Id, user, []string{"email_verified"})
+}
+
+func (us *UserService) ResendEmailVerification(ctx context.Context, login string) error {
+	user, err := us.userRepository.GetUserByLogin(ctx, login)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return nil
+	}
+	return us.sendEmailVerification(ctx, user)
+}
+
+// RequestPasswordReset never reports whether the email is registered,
+// so it can't be used to enumerate accounts.
+func (us *UserService) RequestPasswordReset(ctx context.Context, request *repository.PasswordResetRequest) error {
+	err := validation.Struct(request)
+	if err != nil {
+		return err
+	}
+
+	users, err := us.userRepository.GetUsersByEmail(ctx, request.Email)
+	if err != nil {
+		return err
+	}
+
+	for i := range users {
+		token, err := us.issueToken(ctx, &users[i], repository.TokenPurposePasswordReset, us.cfg.PasswordResetTTL)
+		if err != nil {
+			return err
+		}
+
+		body := fmt.Sprintf("Hello, %s!\n\nTo set a new password open the link below, it is valid for %s:\n%s\n\n"+
+			"If you didn't request a password reset, ignore this email.",
+			users[i].Login, us.cfg.PasswordResetTTL, us.link(resetPasswordPage, token))
+		err = us.mailSender.Send(ctx, users[i].Email, "Password reset", body)
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to send password reset", "error", err)
+		}
+	}
+	return nil
+}
+
+func (us *UserService) ConfirmPasswordReset(ctx context.Context, confirm *repository.PasswordResetConfirm) error {
+	err := validation.Struct(confirm)
+	if err != nil {
+		return err
+	}
+
+	token, err := us.tokenRepository.ConsumeToken(ctx, hashToken(confirm.Token), repository.TokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+
+	return us.userRepository.ResetPassword(ctx, token.UserId, confirm.NewPassword)
+}
+
+func (us *UserService) sendEmailVerification(ctx context.Context, user *repository.User) error {
+	token, err := us.issueToken(ctx, user, repository.TokenPurposeEmailVerification, us.cfg.EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Hello, %s!\n\nTo confirm your email open the link below, it is valid for %s:\n%s",
+		user.Login, us.cfg.EmailVerificationTTL, us.link(verifyEmailPage, token))
+	return us.mailSender.Send(ctx, user.Email, "Confirm your email", body)
+}
+
+// issueToken stores only a hash of the token, the token itself is sent to the user.
+func (us *UserService) issueToken(ctx context.Context, user *repository.User, purpose string, ttl time.Duration) (string, error) {
+	raw := make([]byte, 32)
+	_, err := rand.Read(raw)
+	if err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	err = us.tokenRepository.CreateToken(ctx, &repository.Token{
+		UserId:    user.Id,
+		Purpose:   purpose,
+		Hash:      hashToken(token),
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Pages of the frontend the emailed links open, see config.AppBaseURL.
+const (
+	verifyEmailPage   = "/verify-email"
+	resetPasswordPage = "/reset-password"
+)
+
+// link is the address of a frontend page that is given the token.
+func (us *UserService) link(page string, token string) string {
+	return us.cfg.AppBaseURL + page + "?token=" + url.QueryEscape(token)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"social-network/pkg/logger"
+	"social-network/user-service/internal/config"
+	"strings"
+)
+
+// ErrNotFound is returned by Open when no file is stored under the key.
+var ErrNotFound = errors.New("file not found")
+
+// Storage keeps uploaded files such as avatars. Keys are slash-separated
+// paths chosen by the service, never by the client.
+type Storage interface {
+	Save(ctx context.Context, key string, r io.Reader) error
+	Open(key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+func NewStorage(cfg *config.Config) Storage {
+	return NewLocalStorage(cfg.StorageDir)
+}
+
+// LocalStorage keeps files on the local disk under dir.
+type LocalStorage struct {
+	dir string
+}
+
+func NewLocalStorage(dir string) *LocalStorage {
+	return &LocalStorage{
+		dir: dir,
+	}
+}
+
+// Save writes to a temporary file first so a failed upload never replaces a stored file.
+func (ls *LocalStorage) Save(ctx context.Context, key string, r io.Reader) error {
+	path, err := ls.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to create storage dir", "error", err)
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to create temp file", "error", err)
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "failed to write file", "key", key, "error", err)
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func (ls *LocalStorage) Open(key string) (io.ReadCloser, error) {
+	path, err := ls.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+func (ls *LocalStorage) Delete(ctx context.Context, key string) error {
+	path, err := ls.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.ErrorContext(ctx, "failed to delete file", "key", key, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (ls *LocalStorage) path(key string) (string, error) {
+	if !filepath.IsLocal(key) || strings.HasPrefix(filepath.Base(key), ".") {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return filepath.Join(ls.dir, filepath.FromSlash(key)), nil
+}
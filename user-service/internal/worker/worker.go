@@ -0,0 +1,53 @@
+package worker
+
+import (
+	"context"
+	"go.uber.org/fx"
+	"social-network/pkg/logger"
+	"social-network/user-service/internal/config"
+	"social-network/user-service/internal/outbox"
+	"social-network/user-service/internal/service"
+	"sync"
+	"time"
+)
+
+// InvokeWorkers runs the background jobs: purging accounts whose deletion
+// grace period is over and delivering outbox events.
+func InvokeWorkers(lc fx.Lifecycle, cfg *config.Config, userService service.UserServiceInterface, dispatcher *outbox.Dispatcher) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			wg.Add(2)
+			go every(ctx, &wg, "purge accounts", cfg.PurgeInterval, userService.PurgeDueAccounts)
+			go every(ctx, &wg, "dispatch outbox", cfg.OutboxInterval, dispatcher.Dispatch)
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			cancel()
+			wg.Wait()
+			return nil
+		},
+	})
+}
+
+func every(ctx context.Context, wg *sync.WaitGroup, name string, interval time.Duration, job func(context.Context) error) {
+	defer wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := job(ctx)
+		if err != nil {
+			logger.ErrorContext(ctx, "worker run failed", "worker", name, "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}